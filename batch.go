@@ -0,0 +1,195 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchEventHandler provides an interface for an event handler processing events in batches.
+type BatchEventHandler[T any] interface {
+	Handle(ctx context.Context, events []T) error
+}
+
+// BatchEventHandlerFunc type is an adapter to allow the use of ordinary functions as batch event handlers.
+type BatchEventHandlerFunc[T any] func(ctx context.Context, events []T) error
+
+func (f BatchEventHandlerFunc[T]) Handle(ctx context.Context, events []T) error {
+	return f(ctx, events)
+}
+
+// A Batching configures a batch event handler.
+type Batching[T any] struct {
+	// Size is a maximum size of a batch. A batch is flushed once it reaches the size. Required.
+	Size int
+	// MaxWait is a maximum time an event waits in a batch. A batch is flushed once its first event
+	// waits that long. Zero means a batch is flushed only once it reaches its size.
+	MaxWait time.Duration
+	// OnError is called with a batch and an error returned by a batch event handler.
+	// If nil, the error is passed to the Mob's error handler.
+	OnError func(ctx context.Context, events []T, err error)
+}
+
+// maxQueuedBatches is a maximum number of batches waiting for a flush. Once it's reached,
+// Notify blocks until the oldest batch is flushed.
+const maxQueuedBatches = 16
+
+// A batch is a batch of events waiting for a flush.
+type batch[T any] struct {
+	ctx    context.Context
+	events []T
+}
+
+// A batcher collects notified events and flushes them to the embedded batch event handler.
+type batcher[T any] struct {
+	embedded BatchEventHandler[T]
+	cfg      Batching[T]
+	m        *Mob
+	name     string
+	// mu guards the current batch, stopped and sends to queue.
+	mu      sync.Mutex
+	events  []T
+	ctx     context.Context
+	timer   Timer
+	gen     uint64
+	stopped bool
+	// queue is drained by the flusher goroutine, so batches are handled in order.
+	queue chan batch[T]
+	done  chan struct{}
+}
+
+func newBatcher[T any](m *Mob, bhn BatchEventHandler[T], cfg Batching[T], name string) *batcher[T] {
+	return &batcher[T]{
+		embedded: bhn,
+		cfg:      cfg,
+		m:        m,
+		name:     name,
+		queue:    make(chan batch[T], maxQueuedBatches),
+		done:     make(chan struct{}),
+	}
+}
+
+// Handle adds an event to the current batch. If the batch reaches its size, it's queued for a flush.
+// Once the batcher is stopped, the event is flushed right away as a batch on its own.
+func (b *batcher[T]) Handle(ctx context.Context, event T) error {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		b.flush(batch[T]{ctx: detach(ctx), events: []T{event}})
+		return nil
+	}
+	if len(b.events) == 0 {
+		b.ctx = detach(ctx)
+		if b.cfg.MaxWait > 0 {
+			gen := b.gen
+			b.timer = b.m.clock.AfterFunc(b.cfg.MaxWait, func() { b.flushGen(gen) })
+		}
+	}
+	b.events = append(b.events, event)
+	if len(b.events) >= b.cfg.Size {
+		b.enqueueLocked()
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// flushGen queues the current batch for a flush if it's still the batch of a given generation.
+func (b *batcher[T]) flushGen(gen uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.gen != gen || b.stopped {
+		return
+	}
+	b.enqueueLocked()
+}
+
+// enqueueLocked takes the current batch and queues it for a flush. It must be called with b.mu held.
+func (b *batcher[T]) enqueueLocked() {
+	events, ctx := b.events, b.ctx
+	b.events, b.ctx = nil, nil
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(events) > 0 {
+		b.queue <- batch[T]{ctx: ctx, events: events}
+	}
+}
+
+// run flushes queued batches until the queue is closed.
+func (b *batcher[T]) run() {
+	defer close(b.done)
+	for bt := range b.queue {
+		b.flush(bt)
+	}
+}
+
+// flush passes a given batch to the embedded handler and reports its error, if any.
+func (b *batcher[T]) flush(bt batch[T]) {
+	if err := b.embedded.Handle(bt.ctx, bt.events); err != nil {
+		if b.name != "" {
+			err = fmt.Errorf("%s: %w", b.name, err)
+		}
+		if b.cfg.OnError != nil {
+			b.cfg.OnError(bt.ctx, bt.events, err)
+			return
+		}
+		handleError(bt.ctx, b.m, err)
+	}
+}
+
+// stop flushes the current batch and waits for queued batches to be flushed.
+func (b *batcher[T]) stop() {
+	b.mu.Lock()
+	if !b.stopped {
+		b.enqueueLocked()
+		b.stopped = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+// RegisterBatchEventHandlerTo adds a given batch event handler to the given Mob instance.
+// Returns nil if the handler added successfully, an error otherwise.
+//
+// Notified events are collected in batches configured by a given Batching and flushed to the handler
+// by a background goroutine, one batch at a time. Notify returns once an event is added to a batch,
+// so errors returned by the handler are reported through the Batching's OnError. Notify blocks only if
+// the handler falls behind by more than 16 batches. Pending batches are flushed when the Mob instance is closed.
+func RegisterBatchEventHandlerTo[T any](m *Mob, bhn BatchEventHandler[T], cfg Batching[T], opts ...Option) error {
+	if !isValid(bhn) {
+		return ErrInvalidHandler
+	}
+	if cfg.Size < 1 || cfg.MaxWait < 0 {
+		return fmt.Errorf("%w: batching requires positive size and non-negative max wait", ErrInvalidOption)
+	}
+	// Options are applied to a handler during the registration, the name is needed beforehand for flush errors.
+	var named handler
+	for _, opt := range opts {
+		opt.apply(&named)
+	}
+	b := newBatcher(m, bhn, cfg, named.name)
+	if err := startBackground(m, b); err != nil {
+		return err
+	}
+	go b.run()
+	if _, err := registerEventHandler[T](m, b, opts); err != nil {
+		stopBackground(m, b)
+		b.stop()
+		return err
+	}
+	return nil
+}
+
+// RegisterBatchEventHandler adds a given batch event handler to the global Mob instance.
+// Returns nil if the handler added successfully, an error otherwise.
+//
+// Notified events are collected in batches configured by a given Batching and flushed to the handler.
+// Notify returns once an event is added to a batch, so errors returned by the handler are reported
+// through the Batching's OnError. Pending batches are flushed when the Mob instance is closed.
+func RegisterBatchEventHandler[T any](hn BatchEventHandler[T], cfg Batching[T], opts ...Option) error {
+	return RegisterBatchEventHandlerTo(m, hn, cfg, opts...)
+}
@@ -0,0 +1,164 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterBatchEventHandler(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	var batches [][]int
+	var bhf BatchEventHandlerFunc[DummyEvent1] = func(_ context.Context, events []DummyEvent1) error {
+		var batch []int
+		for _, ev := range events {
+			batch = append(batch, ev.Int)
+		}
+		batches = append(batches, batch)
+		return nil
+	}
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{Size: 3, MaxWait: time.Second}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	nf := NewEventNotifier[DummyEvent1](m)
+	notify := func(i int) {
+		if err := nf.Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	// Flushed by size.
+	for i := 1; i <= 4; i++ {
+		notify(i)
+	}
+	// Flushed by max wait.
+	c.Advance(500 * time.Millisecond)
+	notify(5)
+	c.Advance(500 * time.Millisecond)
+	// Flushed on close.
+	notify(6)
+	m.Close()
+	want := [][]int{{1, 2, 3}, {4, 5}, {6}}
+	if len(batches) != len(want) {
+		t.Fatalf("want batches %v, got %v", want, batches)
+	}
+	for i := range want {
+		if len(batches[i]) != len(want[i]) {
+			t.Fatalf("want batches %v, got %v", want, batches)
+		}
+		for j := range want[i] {
+			if batches[i][j] != want[i][j] {
+				t.Errorf("want batches %v, got %v", want, batches)
+			}
+		}
+	}
+	if n := c.Timers(); n != 0 {
+		t.Errorf("want timers stopped, got %d pending", n)
+	}
+}
+
+func TestRegisterBatchEventHandler_OnError(t *testing.T) {
+	m := New()
+	errDummy := errors.New("dummy")
+	var bhf BatchEventHandlerFunc[DummyEvent1] = func(context.Context, []DummyEvent1) error { return errDummy }
+	var failed []DummyEvent1
+	var gotErr error
+	cfg := Batching[DummyEvent1]{
+		Size: 2,
+		OnError: func(_ context.Context, events []DummyEvent1, err error) {
+			failed = events
+			gotErr = err
+		},
+	}
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, cfg, WithName("Batch")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	nf := NewEventNotifier[DummyEvent1](m)
+	for i := 0; i < 2; i++ {
+		if err := nf.Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	// Closing waits for queued batches to be flushed.
+	m.Close()
+	if len(failed) != 2 {
+		t.Errorf("want failed batch of 2, got %v", failed)
+	}
+	if !errors.Is(gotErr, errDummy) || !strings.HasPrefix(gotErr.Error(), "Batch: ") {
+		t.Errorf("want named %v, got %v", errDummy, gotErr)
+	}
+}
+
+func TestRegisterBatchEventHandler_ErrorHandler(t *testing.T) {
+	m := New()
+	errDummy := errors.New("dummy")
+	var handled error
+	SetErrorHandlerTo(m, func(_ context.Context, err error) { handled = err })
+	var bhf BatchEventHandlerFunc[DummyEvent1] = func(context.Context, []DummyEvent1) error { return errDummy }
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{Size: 1}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	m.Close()
+	if !errors.Is(handled, errDummy) {
+		t.Errorf("want %v handled, got %v", errDummy, handled)
+	}
+}
+
+func TestRegisterBatchEventHandler_NotifyNotBlocked(t *testing.T) {
+	m := New()
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var bhf BatchEventHandlerFunc[DummyEvent1] = func(context.Context, []DummyEvent1) error {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{Size: 1}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	nf := NewEventNotifier[DummyEvent1](m)
+	if err := nf.Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	<-entered
+	// The first batch is still being flushed, the next full batch is queued.
+	done := make(chan error)
+	go func() { done <- nf.Notify(context.Background(), DummyEvent1{}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want notify not blocked by a flush")
+	}
+	close(release)
+	m.Close()
+}
+
+func TestRegisterBatchEventHandler_Invalid(t *testing.T) {
+	m := New()
+	var bhf BatchEventHandlerFunc[DummyEvent1] = func(context.Context, []DummyEvent1) error { return nil }
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, nil, Batching[DummyEvent1]{Size: 1}); err != ErrInvalidHandler {
+		t.Errorf("want err %v, got %v", ErrInvalidHandler, err)
+	}
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{Size: 1}, WithSingleflight()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	m.Close()
+	if err := RegisterBatchEventHandlerTo[DummyEvent1](m, bhf, Batching[DummyEvent1]{Size: 1}); err != ErrClosed {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+}
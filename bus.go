@@ -0,0 +1,222 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults of redelivery of messages by a LoopbackBus and a Broker.
+const (
+	// DefaultRedeliveryDelay is a delay before a message which isn't acknowledged is redelivered.
+	DefaultRedeliveryDelay = 100 * time.Millisecond
+	// DefaultMaxDeliveries is a maximum number of deliveries of a message to a consumer group.
+	DefaultMaxDeliveries = 10
+)
+
+// A Message is an event transported by an EventBus.
+type Message struct {
+	// ID is a unique identifier of the message.
+	ID string `json:"id"`
+	// Type is a name of the event's type.
+	Type string `json:"type"`
+	// Payload is the encoded event.
+	Payload []byte `json:"payload"`
+	// Metadata is arbitrary data transported along with the event.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// Deliveries is a number of deliveries of the message to a consumer group, including the current one.
+	// It's set by an EventBus on delivery, zero if the bus doesn't count deliveries.
+	Deliveries int `json:"deliveries,omitempty"`
+	// MaxDeliveries is a maximum number of deliveries of the message to a consumer group, set by an EventBus
+	// on delivery. Zero means the bus doesn't limit deliveries.
+	MaxDeliveries int `json:"max_deliveries,omitempty"`
+}
+
+// last reports whether the message won't be redelivered if it isn't acknowledged.
+func (msg Message) last() bool {
+	return msg.MaxDeliveries == 0 || msg.Deliveries >= msg.MaxDeliveries
+}
+
+// EventBus provides an interface for a transport of events between Mob instances, e.g. a message broker.
+type EventBus interface {
+	// Publish publishes a message to all consumer groups.
+	Publish(ctx context.Context, msg Message) error
+	// Consume delivers messages published to a given consumer group to f until the context is done,
+	// then returns the context's error. Each message is delivered to a single consumer of the group.
+	// A message is acknowledged if f returns nil, otherwise it's redelivered, unless it's been delivered
+	// a maximum number of times.
+	Consume(ctx context.Context, group string, f func(ctx context.Context, msg Message) error) error
+}
+
+// NewBusEventNotifier returns an event notifier which publishes events to a given EventBus.
+// Events are encoded as JSON and named after types in the given Mob instance's type registry.
+// Metadata of the context is published along with an event.
+func NewBusEventNotifier[T any](m *Mob, bus EventBus) EventNotifier[T] {
+	return &busNotifier[T]{m: m, bus: bus}
+}
+
+type busNotifier[T any] struct {
+	m   *Mob
+	bus EventBus
+}
+
+func (nf *busNotifier[T]) Notify(ctx context.Context, event T) error {
+	name, payload, err := EncodeTo(nf.m, JSONCodec{}, event)
+	if err != nil {
+		return err
+	}
+	return nf.bus.Publish(ctx, Message{ID: newID(), Type: name, Payload: payload, Metadata: MetadataFrom(ctx)})
+}
+
+// ConsumeTo consumes messages published to a given consumer group of an EventBus and notifies their events
+// to the given Mob instance until the context is done, then returns the context's error.
+// Events are notified with a context carrying metadata published along with them.
+//
+// A message is acknowledged once its event is handled, or if no handler is registered for the event's type.
+// If handlers fail, the message is redelivered. Failed handlers dead-letter the event only on its last
+// delivery, if the bus limits deliveries. A message which can't be decoded is acknowledged
+// and the error is passed to the Mob's error handler.
+func ConsumeTo(ctx context.Context, m *Mob, bus EventBus, group string) error {
+	return bus.Consume(ctx, group, func(ctx context.Context, msg Message) error {
+		ev, err := DecodeTo(m, JSONCodec{}, msg.Type, msg.Payload)
+		if err != nil {
+			handleError(ctx, m, fmt.Errorf("mob: consume message %s: %w", msg.ID, err))
+			return nil
+		}
+		nctx := withMetadata(ctx, msg.Metadata)
+		if !msg.last() {
+			nctx = withoutDeadLetters(nctx)
+		}
+		// Compared directly, so handlers' errors wrapping ErrHandlerNotFound are redelivered.
+		if err := notifyAny(nctx, m, ev); err != nil && err != ErrHandlerNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// Consume consumes messages published to a given consumer group of an EventBus and notifies their events
+// to the global Mob instance until the context is done.
+func Consume(ctx context.Context, bus EventBus, group string) error {
+	return ConsumeTo(ctx, m, bus, group)
+}
+
+// A LoopbackBus is an in-process EventBus. A consumer group is created by its first consumer,
+// messages published before are not delivered to it. Redelivered messages are put at the end of their group's queue
+// after a redelivery delay. A message which isn't acknowledged after a maximum number of deliveries is dropped.
+type LoopbackBus struct {
+	mu            sync.Mutex
+	groups        map[string]*busGroup
+	delay         time.Duration
+	maxDeliveries int
+}
+
+// NewLoopbackBus returns a LoopbackBus without consumer groups, redelivering messages
+// after DefaultRedeliveryDelay at most DefaultMaxDeliveries times.
+func NewLoopbackBus() *LoopbackBus {
+	return &LoopbackBus{groups: map[string]*busGroup{}, delay: DefaultRedeliveryDelay, maxDeliveries: DefaultMaxDeliveries}
+}
+
+// SetRedelivery sets a delay before a message which isn't acknowledged is redelivered and a maximum number
+// of deliveries of a message to a consumer group. Zero maxDeliveries means messages are redelivered until
+// they're acknowledged.
+func (b *LoopbackBus) SetRedelivery(delay time.Duration, maxDeliveries int) {
+	b.mu.Lock()
+	b.delay, b.maxDeliveries = delay, maxDeliveries
+	b.mu.Unlock()
+}
+
+// A busGroup is a queue of messages of a consumer group.
+type busGroup struct {
+	mu    sync.Mutex
+	queue []Message
+	// ready is signaled when a message is queued.
+	ready chan struct{}
+}
+
+func (g *busGroup) put(msg Message) {
+	g.mu.Lock()
+	g.queue = append(g.queue, msg)
+	g.mu.Unlock()
+	g.signal()
+}
+
+func (g *busGroup) signal() {
+	select {
+	case g.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take waits for a message or until the context is done.
+func (g *busGroup) take(ctx context.Context) (Message, error) {
+	for {
+		g.mu.Lock()
+		if len(g.queue) > 0 {
+			msg := g.queue[0]
+			g.queue = g.queue[1:]
+			more := len(g.queue) > 0
+			g.mu.Unlock()
+			if more {
+				// Wake up another consumer.
+				g.signal()
+			}
+			return msg, nil
+		}
+		g.mu.Unlock()
+		select {
+		case <-g.ready:
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		}
+	}
+}
+
+// Publish publishes a message to all consumer groups.
+func (b *LoopbackBus) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg.Deliveries, msg.MaxDeliveries = 0, 0
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, g := range b.groups {
+		g.put(msg)
+	}
+	return nil
+}
+
+// Consume delivers messages published to a given consumer group to f until the context is done.
+// A message is acknowledged if f returns nil, otherwise it's redelivered after the redelivery delay,
+// unless it's been delivered the maximum number of times.
+func (b *LoopbackBus) Consume(ctx context.Context, group string, f func(ctx context.Context, msg Message) error) error {
+	b.mu.Lock()
+	g, ok := b.groups[group]
+	if !ok {
+		g = &busGroup{ready: make(chan struct{}, 1)}
+		b.groups[group] = g
+	}
+	b.mu.Unlock()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msg, err := g.take(ctx)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		delay := b.delay
+		msg.MaxDeliveries = b.maxDeliveries
+		b.mu.Unlock()
+		msg.Deliveries++
+		if err := f(ctx, msg); err != nil && (msg.MaxDeliveries == 0 || msg.Deliveries < msg.MaxDeliveries) {
+			if delay <= 0 {
+				g.put(msg)
+				continue
+			}
+			time.AfterFunc(delay, func() { g.put(msg) })
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// consumeN consumes messages of a given group until n messages are acknowledged.
+func consumeN(t *testing.T, bus EventBus, group string, n int, f func(msg Message) error) []Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var acked []Message
+	err := bus.Consume(ctx, group, func(_ context.Context, msg Message) error {
+		if err := f(msg); err != nil {
+			return err
+		}
+		acked = append(acked, msg)
+		if len(acked) == n {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want err %v, got %v", context.Canceled, err)
+	}
+	return acked
+}
+
+func TestLoopbackBus(t *testing.T) {
+	bus := NewLoopbackBus()
+	ready := make(chan struct{})
+	var wg sync.WaitGroup
+	var a, b []Message
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		redelivered := false
+		a = consumeN(t, bus, "a", 2, func(msg Message) error {
+			if msg.ID == "1" && !redelivered {
+				redelivered = true
+				return errors.New("dummy")
+			}
+			return nil
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		b = consumeN(t, bus, "b", 2, func(Message) error { return nil })
+	}()
+	go func() {
+		// Wait for both groups to be created.
+		for {
+			bus.mu.Lock()
+			n := len(bus.groups)
+			bus.mu.Unlock()
+			if n == 2 {
+				close(ready)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-ready
+	for _, id := range []string{"1", "2"} {
+		if err := bus.Publish(context.Background(), Message{ID: id}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	wg.Wait()
+	if len(a) != 2 || a[0].ID != "2" || a[1].ID != "1" {
+		t.Errorf("want nacked message redelivered after the next one, got %v", a)
+	}
+	if len(b) != 2 || b[0].ID != "1" || b[1].ID != "2" {
+		t.Errorf("want all messages delivered to each group, got %v", b)
+	}
+}
+
+func TestLoopbackBus_ConsumerGroup(t *testing.T) {
+	bus := NewLoopbackBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	consumers := map[int]int{}
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	var total int
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = bus.Consume(ctx, "g", func(context.Context, Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				consumers[i]++
+				total++
+				if total == 10 {
+					close(done)
+				}
+				return nil
+			})
+		}(i)
+	}
+	for {
+		bus.mu.Lock()
+		n := len(bus.groups)
+		bus.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		if err := bus.Publish(context.Background(), Message{}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	<-done
+	cancel()
+	wg.Wait()
+	if total != 10 {
+		t.Errorf("want each message delivered once within a group, got %d deliveries", total)
+	}
+}
+
+func TestBusEventNotifier_ConsumeTo(t *testing.T) {
+	bus := NewLoopbackBus()
+	m := New()
+	got := make(chan DummyEvent1, 1)
+	var fail = true
+	hn := &DummyEventHandler1{handleFunc: func(_ context.Context, ev DummyEvent1) error {
+		if fail {
+			fail = false
+			return errors.New("dummy")
+		}
+		got <- ev
+		return nil
+	}}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	var errs []error
+	SetErrorHandlerTo(m, func(_ context.Context, err error) { errs = append(errs, err) })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ConsumeTo(ctx, m, bus, "g") }()
+	for {
+		bus.mu.Lock()
+		n := len(bus.groups)
+		bus.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The publisher doesn't need handlers, only the types.
+	if err := NewBusEventNotifier[DummyEvent1](New(), bus).Notify(context.Background(), DummyEvent1{Int: 997}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if ev := <-got; ev.Int != 997 {
+		t.Errorf("want event consumed, got %v", ev)
+	}
+	if err := bus.Publish(context.Background(), Message{ID: "malformed", Type: "github.com/erni27/mob.DummyEvent1", Payload: []byte("{")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for {
+		bus.mu.Lock()
+		g := bus.groups["g"]
+		bus.mu.Unlock()
+		g.mu.Lock()
+		n := len(g.queue)
+		g.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("want err %v, got %v", context.Canceled, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnmarshal) {
+		t.Errorf("want malformed message reported, got %v", errs)
+	}
+	if hn.Calls() != 2 {
+		t.Errorf("want failed event redelivered, got %d calls", hn.Calls())
+	}
+}
+
+func TestLoopbackBus_Redelivery(t *testing.T) {
+	bus := NewLoopbackBus()
+	bus.SetRedelivery(20*time.Millisecond, 3)
+	m := New()
+	q := NewMemoryDeadLetterQueue()
+	SetDeadLetterQueueTo(m, q)
+	var mu sync.Mutex
+	var deliveries []time.Time
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error {
+		mu.Lock()
+		deliveries = append(deliveries, time.Now())
+		mu.Unlock()
+		return errors.New("dummy")
+	}}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = ConsumeTo(ctx, m, bus, "g") }()
+	for {
+		bus.mu.Lock()
+		n := len(bus.groups)
+		bus.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := NewBusEventNotifier[DummyEvent1](m, bus).Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give a further redelivery, if any, a chance to happen.
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deliveries) != 3 {
+		t.Fatalf("want 3 deliveries, got %d", len(deliveries))
+	}
+	for i := 1; i < len(deliveries); i++ {
+		if d := deliveries[i].Sub(deliveries[i-1]); d < 20*time.Millisecond {
+			t.Errorf("want redelivery delayed, got %v", d)
+		}
+	}
+	if q.Len() != 1 {
+		t.Errorf("want event dead-lettered once on its last delivery, got %d dead letters", q.Len())
+	}
+}
@@ -0,0 +1,126 @@
+package mob
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Clock provides the current time and timers to a Mob instance.
+// It allows to control time-based behavior in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer is a single event timer created by a Clock.
+type Timer interface {
+	// Stop prevents the Timer from firing.
+	// It returns true if the call stops the timer, false if the timer has already expired or been stopped.
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// SetClockTo sets a Clock used by the given Mob instance.
+// By default, a Mob instance uses the system clock.
+func SetClockTo(m *Mob, c Clock) {
+	m.clock = c
+}
+
+// SetClock sets a Clock used by the global Mob instance.
+// By default, the global Mob instance uses the system clock.
+func SetClock(c Clock) {
+	SetClockTo(m, c)
+}
+
+// A ManualClock is a Clock whose time is changed manually. Timers created by a ManualClock
+// fire synchronously, in order of their deadlines, when the clock's time is advanced past them.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock returns a ManualClock set to a given time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc returns a Timer calling f once the clock is advanced by at least d.
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{c: c, deadline: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock's time forward by d firing all timers whose deadlines passed.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+		if len(c.timers) == 0 || c.timers[0].deadline.After(target) {
+			// The clock might have been advanced further concurrently.
+			if target.After(c.now) {
+				c.now = target
+			}
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.deadline.After(c.now) {
+			c.now = t.deadline
+		}
+		c.mu.Unlock()
+		// Called without holding the lock, so f can create new timers.
+		t.f()
+	}
+}
+
+// Timers returns the number of pending timers.
+func (c *ManualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+type manualTimer struct {
+	c        *ManualClock
+	deadline time.Time
+	f        func()
+}
+
+func (t *manualTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	for i, pending := range t.c.timers {
+		if pending == t {
+			t.c.timers = append(t.c.timers[:i:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
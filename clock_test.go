@@ -0,0 +1,41 @@
+package mob
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+	var fired []time.Time
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, c.Now()) })
+	c.AfterFunc(time.Second, func() {
+		fired = append(fired, c.Now())
+		// Timers can be created from within a timer's function.
+		c.AfterFunc(500*time.Millisecond, func() { fired = append(fired, c.Now()) })
+	})
+	stopped := c.AfterFunc(time.Second, func() { t.Error("want stopped timer not fired") })
+	if !stopped.Stop() {
+		t.Error("want pending timer stopped")
+	}
+	if stopped.Stop() {
+		t.Error("want stopped timer not stopped again")
+	}
+	c.Advance(3 * time.Second)
+	want := []time.Time{start.Add(time.Second), start.Add(1500 * time.Millisecond), start.Add(2 * time.Second)}
+	if len(fired) != len(want) {
+		t.Fatalf("want timers fired at %v, got %v", want, fired)
+	}
+	for i := range want {
+		if !fired[i].Equal(want[i]) {
+			t.Errorf("want timers fired at %v, got %v", want, fired)
+		}
+	}
+	if now := c.Now(); !now.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("want %v, got %v", start.Add(3*time.Second), now)
+	}
+	if n := c.Timers(); n != 0 {
+		t.Errorf("want no pending timers, got %d", n)
+	}
+}
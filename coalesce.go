@@ -0,0 +1,134 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// An Edge determines on which edge of a window a debounced or throttled handler is executed.
+type Edge int
+
+const (
+	// TrailingEdge executes a handler at the end of a window with the last event notified within the window.
+	TrailingEdge Edge = iota
+	// LeadingEdge executes a handler at the beginning of a window with the first event notified within the window.
+	LeadingEdge
+)
+
+// A coalescing configures debouncing or throttling of an event handler.
+type coalescing[T any] struct {
+	window time.Duration
+	edge   Edge
+	key    func(event T) string
+	// debounce extends a window with each event, otherwise a window has a fixed length.
+	debounce bool
+}
+
+// A window is a state of a coalescing window for a single key.
+type window[T any] struct {
+	timer   Timer
+	event   T
+	ctx     context.Context
+	pending bool
+}
+
+// A coalescingEventHandler executes the embedded handler at most once per window.
+type coalescingEventHandler[T any] struct {
+	embedded EventHandler[T]
+	cfg      coalescing[T]
+	m        *Mob
+	// hn is the registered handler, trailing executions are traced, instrumented and dead-lettered as its own.
+	hn      *handler
+	mu      sync.Mutex
+	windows map[string]*window[T]
+}
+
+func newCoalescingEventHandler[T any](m *Mob, hn *handler, ehn EventHandler[T], cfg coalescing[T]) (*coalescingEventHandler[T], error) {
+	if cfg.window <= 0 || (cfg.edge != TrailingEdge && cfg.edge != LeadingEdge) {
+		return nil, fmt.Errorf("%w: coalescing requires positive window and known edge", ErrInvalidOption)
+	}
+	h := &coalescingEventHandler[T]{embedded: ehn, cfg: cfg, m: m, hn: hn, windows: map[string]*window[T]{}}
+	if err := startBackground(m, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *coalescingEventHandler[T]) Handle(ctx context.Context, event T) error {
+	var k string
+	if h.cfg.key != nil {
+		k = h.cfg.key(event)
+	}
+	h.mu.Lock()
+	w, active := h.windows[k]
+	if !active {
+		w = &window[T]{}
+		h.windows[k] = w
+		w.timer = h.m.clock.AfterFunc(h.cfg.window, func() { h.close(k, w) })
+	} else if h.cfg.debounce && w.timer.Stop() {
+		w.timer = h.m.clock.AfterFunc(h.cfg.window, func() { h.close(k, w) })
+	}
+	if h.cfg.edge == TrailingEdge {
+		w.event = event
+		w.ctx = detach(ctx)
+		w.pending = true
+		h.mu.Unlock()
+		return nil
+	}
+	h.mu.Unlock()
+	if active {
+		return nil
+	}
+	return h.embedded.Handle(ctx, event)
+}
+
+// close closes a given window executing the embedded handler if there is a pending event.
+func (h *coalescingEventHandler[T]) close(k string, w *window[T]) {
+	h.mu.Lock()
+	if h.windows[k] == w {
+		delete(h.windows, k)
+	}
+	pending, ctx, event := w.pending, w.ctx, w.event
+	h.mu.Unlock()
+	if pending {
+		h.trail(ctx, event)
+	}
+}
+
+// trail executes the embedded handler with a pending event on the trailing edge of a window.
+// It's executed asynchronously, so its error is dead-lettered and passed to the Mob's error handler.
+func (h *coalescingEventHandler[T]) trail(ctx context.Context, event T) {
+	ctx, endSpan := trace(ctx, h.m, SpanHandle, event, h.hn)
+	end := instrument(h.m, MetricNotify, event, h.hn)
+	err := h.embedded.Handle(ctx, event)
+	end(err)
+	endSpan(err)
+	if err != nil {
+		deadLetter(ctx, h.m, h.hn, event, err)
+		if h.hn.name != "" {
+			err = fmt.Errorf("%s: %w", h.hn.name, err)
+		}
+		handleError(ctx, h.m, err)
+	}
+}
+
+// stop closes all windows, so pending events are handled before the Mob instance is closed.
+func (h *coalescingEventHandler[T]) stop() {
+	h.mu.Lock()
+	var pending []window[T]
+	for k, w := range h.windows {
+		w.timer.Stop()
+		delete(h.windows, k)
+		if w.pending {
+			pending = append(pending, *w)
+			// A timer which has already fired mustn't handle the event again.
+			w.pending = false
+		}
+	}
+	h.mu.Unlock()
+	for _, w := range pending {
+		h.trail(w.ctx, w.event)
+	}
+}
@@ -0,0 +1,179 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A coalescingStep is a notification of an event preceded by advancing a clock by a delay.
+type coalescingStep struct {
+	delay time.Duration
+	ev    DummyEvent1
+}
+
+func TestNotify_Coalescing(t *testing.T) {
+	byString := func(ev DummyEvent1) string { return ev.String }
+	tests := []struct {
+		name  string
+		opt   Option
+		steps []coalescingStep
+		want  []int
+	}{
+		{
+			name:  "debounce trailing",
+			opt:   WithDebounce[DummyEvent1](time.Second, TrailingEdge, nil),
+			steps: []coalescingStep{{0, DummyEvent1{Int: 1}}, {500 * time.Millisecond, DummyEvent1{Int: 2}}, {900 * time.Millisecond, DummyEvent1{Int: 3}}, {1100 * time.Millisecond, DummyEvent1{Int: 4}}},
+			want:  []int{3, 4},
+		},
+		{
+			name:  "debounce leading",
+			opt:   WithDebounce[DummyEvent1](time.Second, LeadingEdge, nil),
+			steps: []coalescingStep{{0, DummyEvent1{Int: 1}}, {500 * time.Millisecond, DummyEvent1{Int: 2}}, {900 * time.Millisecond, DummyEvent1{Int: 3}}, {1100 * time.Millisecond, DummyEvent1{Int: 4}}},
+			want:  []int{1, 4},
+		},
+		{
+			name:  "throttle trailing",
+			opt:   WithThrottle[DummyEvent1](time.Second, TrailingEdge, nil),
+			steps: []coalescingStep{{0, DummyEvent1{Int: 1}}, {500 * time.Millisecond, DummyEvent1{Int: 2}}, {600 * time.Millisecond, DummyEvent1{Int: 3}}, {100 * time.Millisecond, DummyEvent1{Int: 4}}},
+			want:  []int{2, 4},
+		},
+		{
+			name:  "throttle leading",
+			opt:   WithThrottle[DummyEvent1](time.Second, LeadingEdge, nil),
+			steps: []coalescingStep{{0, DummyEvent1{Int: 1}}, {500 * time.Millisecond, DummyEvent1{Int: 2}}, {600 * time.Millisecond, DummyEvent1{Int: 3}}, {100 * time.Millisecond, DummyEvent1{Int: 4}}},
+			want:  []int{1, 3},
+		},
+		{
+			name:  "throttle leading by key",
+			opt:   WithThrottle(time.Second, LeadingEdge, byString),
+			steps: []coalescingStep{{0, DummyEvent1{String: "a", Int: 1}}, {0, DummyEvent1{String: "b", Int: 2}}, {0, DummyEvent1{String: "a", Int: 3}}, {0, DummyEvent1{String: "b", Int: 4}}},
+			want:  []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			c := NewManualClock(time.Now())
+			SetClockTo(m, c)
+			var got []int
+			var ehf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+				got = append(got, ev.Int)
+				return nil
+			}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, ehf, tt.opt); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			nf := NewEventNotifier[DummyEvent1](m)
+			for _, step := range tt.steps {
+				c.Advance(step.delay)
+				if err := nf.Notify(context.Background(), step.ev); err != nil {
+					t.Fatalf("want success, got %v", err)
+				}
+			}
+			c.Advance(time.Hour)
+			if len(got) != len(tt.want) {
+				t.Fatalf("want handled events %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("want handled events %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNotify_CoalescingTrailingError(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	errDummy := errors.New("dummy")
+	var handled []error
+	SetErrorHandlerTo(m, func(_ context.Context, err error) { handled = append(handled, err) })
+	q := NewMemoryDeadLetterQueue()
+	SetDeadLetterQueueTo(m, q)
+	tr := NewRecordingTracer()
+	SetTracerTo(m, tr)
+	var ehf EventHandlerFunc[DummyEvent1] = func(context.Context, DummyEvent1) error { return errDummy }
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf, WithName("Debounced"), WithDebounce[DummyEvent1](time.Second, TrailingEdge, nil)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	c.Advance(time.Second)
+	if len(handled) != 1 || !errors.Is(handled[0], errDummy) || !strings.HasPrefix(handled[0].Error(), "Debounced: ") {
+		t.Errorf("want named %v handled, got %v", errDummy, handled)
+	}
+	dls, _ := q.Drain(context.Background())
+	if len(dls) != 1 || dls[0].Handler != "Debounced" || dls[0].Err != "dummy" {
+		t.Errorf("want trailing failure dead-lettered, got %v", dls)
+	}
+	if spans := tr.Spans(); len(spans) != 3 || spans[2].Name != SpanHandle || len(spans[2].Errs) != 1 {
+		t.Errorf("want trailing execution traced, got %v", spans)
+	}
+}
+
+func TestNotify_CoalescingClose(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithThrottle[DummyEvent1](time.Second, TrailingEdge, nil)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	m.Close()
+	c.Advance(time.Hour)
+	if calls := hn.Calls(); calls != 1 {
+		t.Errorf("want pending event handled once on close, got %d calls", calls)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithThrottle[DummyEvent1](time.Second, TrailingEdge, nil)); err != ErrClosed {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestWithDebounce_InvalidOption(t *testing.T) {
+	defer clear()
+	tests := []struct {
+		name     string
+		register func() error
+	}{
+		{
+			name: "zero window",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithDebounce[DummyEvent1](0, TrailingEdge, nil))
+			},
+		},
+		{
+			name: "unknown edge",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithThrottle[DummyEvent1](time.Second, Edge(997), nil))
+			},
+		},
+		{
+			name: "mismatched key",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithDebounce[string](time.Second, TrailingEdge, nil))
+			},
+		},
+		{
+			name: "request handler",
+			register: func() error {
+				return RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithThrottle[DummyRequest1](time.Second, LeadingEdge, nil))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.register(); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+		})
+	}
+}
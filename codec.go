@@ -0,0 +1,45 @@
+package mob
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec provides an interface for encoding and decoding messages.
+type Codec interface {
+	// Marshal encodes a given value.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into a value pointed to by v.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// A JSONCodec is a Codec encoding messages as JSON.
+type JSONCodec struct{}
+
+// Marshal encodes a given value as JSON.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into a value pointed to by v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// A GobCodec is a Codec encoding messages with encoding/gob. Each message is encoded as a self-contained stream.
+type GobCodec struct{}
+
+// Marshal encodes a given value with encoding/gob.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes gob data into a value pointed to by v.
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
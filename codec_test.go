@@ -0,0 +1,32 @@
+package mob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Codec
+	}{
+		{name: "json", c: JSONCodec{}},
+		{name: "gob", c: GobCodec{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := DummyEvent1{String: "string", Int: 997}
+			data, err := tt.c.Marshal(want)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var got DummyEvent1
+			if err := tt.c.Unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package mob
+
+import (
+	"context"
+	"sync"
+)
+
+// A unitOfWorkKey is a context key of a unit of work.
+type unitOfWorkKey struct{}
+
+// A unitOfWork collects events raised while a request is handled.
+type unitOfWork struct {
+	mu     sync.Mutex
+	events []func(ctx context.Context, m *Mob) error
+}
+
+func (u *unitOfWork) add(events ...func(ctx context.Context, m *Mob) error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.events = append(u.events, events...)
+}
+
+func (u *unitOfWork) take() []func(ctx context.Context, m *Mob) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	events := u.events
+	u.events = nil
+	return events
+}
+
+// nestUnitOfWork returns a context carrying a unit of work nested in the one of a given context and a function
+// passing its events to the outer unit of work. If the context carries no unit of work, it's returned as is.
+// It lets decorators discard events raised by a failed attempt of a handler, e.g. one which is retried.
+func nestUnitOfWork(ctx context.Context) (context.Context, func()) {
+	outer, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return ctx, func() {}
+	}
+	u := &unitOfWork{}
+	return context.WithValue(ctx, unitOfWorkKey{}, u), func() { outer.add(u.take()...) }
+}
+
+// Raise adds a given event to the unit of work of a given context. The event is notified once the request
+// the unit of work is opened for is handled successfully, and discarded otherwise.
+// Units of work are opened by an Interceptor returned by CollectEvents or CollectEventsTo.
+// Events raised by a failed attempt of a retried handler, or by a failed primary handler of a fallback,
+// are discarded too.
+//
+// If the context carries no unit of work, ErrNoUnitOfWork is returned. If the context is done, its error is returned.
+func Raise[T any](ctx context.Context, event T) error {
+	u, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return ErrNoUnitOfWork
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	u.add(func(ctx context.Context, m *Mob) error {
+		return NewEventNotifier[T](m).Notify(ctx, event)
+	})
+	return nil
+}
+
+// CollectEventsTo returns an Interceptor that opens a unit of work for each sent request.
+// Events raised with Raise while the request is handled are notified to the given Mob instance in order
+// they're raised, but only if the rest of the chain returns no error. Otherwise they're discarded.
+//
+// A unit of work opened for a request sent while handling another one is nested. Its events are passed
+// to the outer unit of work, so they're notified only if the outermost request is handled successfully.
+//
+// Events without registered handlers are skipped. Errors of notified events are aggregated and returned
+// to the sender.
+func CollectEventsTo(m *Mob) Interceptor {
+	return func(ctx context.Context, req interface{}, invoker SendInvoker) (interface{}, error) {
+		outer, _ := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+		u := &unitOfWork{}
+		res, err := invoker(context.WithValue(ctx, unitOfWorkKey{}, u), req)
+		if err != nil {
+			return res, err
+		}
+		events := u.take()
+		if outer != nil {
+			outer.add(events...)
+			return res, nil
+		}
+		var aggr AggregateHandlerError
+		for _, notify := range events {
+			// Compared directly, so handlers' errors wrapping ErrHandlerNotFound aren't skipped.
+			if err := notify(ctx, m); err != nil && err != ErrHandlerNotFound {
+				aggr = append(aggr, err)
+			}
+		}
+		if len(aggr) > 0 {
+			return res, aggr
+		}
+		return res, nil
+	}
+}
+
+// CollectEvents returns an Interceptor that opens a unit of work for each sent request.
+// Events raised with Raise while the request is handled are notified to the global Mob instance,
+// but only if the rest of the chain returns no error.
+func CollectEvents() Interceptor {
+	return CollectEventsTo(m)
+}
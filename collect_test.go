@@ -0,0 +1,207 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollectEvents(t *testing.T) {
+	errDummy := errors.New("dummy")
+	tests := []struct {
+		name    string
+		handle  func(ctx context.Context, req DummyRequest1) (DummyResponse1, error)
+		opts    []Option
+		wantErr error
+		want    []DummyEvent1
+	}{
+		{
+			name: "success",
+			handle: func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+				if err := Raise(ctx, DummyEvent1{Int: 1}); err != nil {
+					return DummyResponse1{}, err
+				}
+				if err := Raise(ctx, DummyEvent1{Int: 2}); err != nil {
+					return DummyResponse1{}, err
+				}
+				return DummyResponse1{}, nil
+			},
+			want: []DummyEvent1{{Int: 1}, {Int: 2}},
+		},
+		{
+			name: "failure",
+			handle: func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+				if err := Raise(ctx, DummyEvent1{Int: 1}); err != nil {
+					return DummyResponse1{}, err
+				}
+				return DummyResponse1{}, errDummy
+			},
+			wantErr: errDummy,
+		},
+		{
+			name: "failed retry attempt",
+			handle: func() func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+				var attempt int
+				return func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+					attempt++
+					if err := Raise(ctx, DummyEvent1{Int: attempt}); err != nil {
+						return DummyResponse1{}, err
+					}
+					if attempt == 1 {
+						return DummyResponse1{}, errDummy
+					}
+					return DummyResponse1{}, nil
+				}
+			}(),
+			opts: []Option{WithRetry(2, 0)},
+			want: []DummyEvent1{{Int: 2}},
+		},
+		{
+			name: "failed primary",
+			handle: func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+				if err := Raise(ctx, DummyEvent1{Int: 1}); err != nil {
+					return DummyResponse1{}, err
+				}
+				return DummyResponse1{}, errDummy
+			},
+			opts: []Option{WithFallback(Fallback[DummyRequest1, DummyResponse1]{
+				Handler: RequestHandlerFunc[DummyRequest1, DummyResponse1](func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+					return DummyResponse1{}, Raise(ctx, DummyEvent1{Int: 2})
+				}),
+			})},
+			want: []DummyEvent1{{Int: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			AddInterceptorTo(m, CollectEventsTo(m))
+			var got []DummyEvent1
+			var ehf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+				got = append(got, ev)
+				return nil
+			}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+				t.Fatalf("register event handler: %v", err)
+			}
+			if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, RequestHandlerFunc[DummyRequest1, DummyResponse1](tt.handle), tt.opts...); err != nil {
+				t.Fatalf("register request handler: %v", err)
+			}
+			_, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want err %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want events %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCollectEvents_Nested(t *testing.T) {
+	defer clear()
+	AddInterceptor(CollectEvents())
+	errDummy := errors.New("dummy")
+	var got []DummyEvent1
+	var ehf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+		got = append(got, ev)
+		return nil
+	}
+	if err := RegisterEventHandler[DummyEvent1](ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	var inner RequestHandlerFunc[DummyRequest2, DummyResponse2] = func(ctx context.Context, _ DummyRequest2) (DummyResponse2, error) {
+		return DummyResponse2{}, Raise(ctx, DummyEvent1{Int: 2})
+	}
+	if err := RegisterRequestHandler[DummyRequest2, DummyResponse2](inner); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	var outer RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		if err := Raise(ctx, DummyEvent1{Int: 1}); err != nil {
+			return DummyResponse1{}, err
+		}
+		if _, err := Send[DummyRequest2, DummyResponse2](ctx, DummyRequest2{}); err != nil {
+			return DummyResponse1{}, err
+		}
+		if len(got) != 0 {
+			t.Errorf("want no events notified before the outer request is handled, got %v", got)
+		}
+		return DummyResponse1{}, errDummy
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](outer); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); !errors.Is(err, errDummy) {
+		t.Errorf("want err %v, got %v", errDummy, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("want events of nested request discarded, got %v", got)
+	}
+}
+
+func TestCollectEvents_NotifyError(t *testing.T) {
+	defer clear()
+	AddInterceptor(CollectEvents())
+	errDummy := errors.New("dummy")
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errDummy }}); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		// No handler is registered, the event is skipped.
+		if err := Raise(ctx, DummyRequest2{}); err != nil {
+			return DummyResponse1{}, err
+		}
+		return DummyResponse1{}, Raise(ctx, DummyEvent1{})
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); !errors.Is(err, errDummy) {
+		t.Errorf("want err %v, got %v", errDummy, err)
+	}
+}
+
+func TestCollectEvents_TimedOutPrimary(t *testing.T) {
+	m := New()
+	AddInterceptorTo(m, CollectEventsTo(m))
+	var got []DummyEvent1
+	var ehf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+		got = append(got, ev)
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	raised := make(chan error, 1)
+	var primary RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		<-ctx.Done()
+		raised <- Raise(ctx, DummyEvent1{Int: 1})
+		return DummyResponse1{}, nil
+	}
+	fb := Fallback[DummyRequest1, DummyResponse1]{
+		Handler: RequestHandlerFunc[DummyRequest1, DummyResponse1](func(context.Context, DummyRequest1) (DummyResponse1, error) {
+			return DummyResponse1{}, nil
+		}),
+		Timeout: 10 * time.Millisecond,
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, primary, WithFallback(fb)); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if err := <-raised; !errors.Is(err, context.Canceled) {
+		t.Errorf("want err %v raised by abandoned primary, got %v", context.Canceled, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("want no events of timed out primary, got %v", got)
+	}
+}
+
+func TestRaise_NoUnitOfWork(t *testing.T) {
+	if err := Raise(context.Background(), DummyEvent1{}); !errors.Is(err, ErrNoUnitOfWork) {
+		t.Errorf("want err %v, got %v", ErrNoUnitOfWork, err)
+	}
+}
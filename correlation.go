@@ -0,0 +1,141 @@
+package mob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+)
+
+// Metadata keys of IDs assigned to dispatched requests and events.
+const (
+	// MessageIDKey is a metadata key of an ID of a request or an event being handled.
+	MessageIDKey = "mob-message-id"
+	// CausationIDKey is a metadata key of an ID of a request or an event during handling of which
+	// the request or the event being handled was dispatched.
+	CausationIDKey = "mob-causation-id"
+	// CorrelationIDKey is a metadata key of an ID of the first request or event of a chain
+	// of nested dispatches.
+	CorrelationIDKey = "mob-correlation-id"
+)
+
+// MessageID returns an ID of a request or an event handled with a given context, empty if there is none.
+func MessageID(ctx context.Context) string {
+	v := metadataFrom(ctx)
+	if v == nil {
+		return ""
+	}
+	if v.ids != nil {
+		return v.ids.message()
+	}
+	return v.md[MessageIDKey]
+}
+
+// CausationID returns an ID of a request or an event during handling of which a request or an event
+// handled with a given context was dispatched, empty if it wasn't dispatched by a handler.
+func CausationID(ctx context.Context) string {
+	v := metadataFrom(ctx)
+	if v == nil {
+		return ""
+	}
+	if v.ids != nil {
+		return v.ids.causationID()
+	}
+	return v.md[CausationIDKey]
+}
+
+// CorrelationID returns an ID of the first request or event of a chain of nested dispatches
+// a request or an event handled with a given context belongs to, empty if there is none.
+func CorrelationID(ctx context.Context) string {
+	v := metadataFrom(ctx)
+	if v == nil {
+		return ""
+	}
+	if v.ids != nil {
+		return v.ids.correlationID()
+	}
+	return v.md[CorrelationIDKey]
+}
+
+// messageIDs are IDs of a dispatched message. They're formatted only when read,
+// so dispatching doesn't pay for them unless they're used.
+type messageIDs struct {
+	seq uint64
+	// parent is IDs of a message during handling of which the message was dispatched, nil if there is none.
+	parent *messageIDs
+	// causation and correlation are IDs read from metadata of a message without a parent, e.g. a transported one.
+	causation, correlation string
+}
+
+func (ids *messageIDs) message() string {
+	return formatMessageID(ids.seq)
+}
+
+func (ids *messageIDs) causationID() string {
+	if ids.parent != nil {
+		return ids.parent.message()
+	}
+	return ids.causation
+}
+
+func (ids *messageIDs) correlationID() string {
+	for ids.parent != nil {
+		ids = ids.parent
+	}
+	if ids.correlation != "" {
+		return ids.correlation
+	}
+	return ids.message()
+}
+
+// A dispatchContext is a context of a dispatched message. It carries the message's IDs along with
+// the metadata of its parent context in a single allocation.
+type dispatchContext struct {
+	context.Context
+	v   metadataValue
+	ids messageIDs
+}
+
+func (c *dispatchContext) Value(key interface{}) interface{} {
+	if key == (metadataKey{}) {
+		return &c.v
+	}
+	return c.Context.Value(key)
+}
+
+// withMessageID returns a copy of a given context carrying a new message ID, caused by the context's message
+// and correlated with its correlation ID. A message without a cause is correlated with itself, unless
+// the correlation ID is set by the caller.
+func withMessageID(ctx context.Context) context.Context {
+	c := &dispatchContext{Context: ctx, ids: messageIDs{seq: atomic.AddUint64(&messageIDSeq, 1)}}
+	c.v.ids = &c.ids
+	if v := metadataFrom(ctx); v != nil {
+		c.v.md = v.md
+		if v.ids != nil {
+			c.ids.parent = v.ids
+		} else {
+			c.ids.causation, c.ids.correlation = v.md[MessageIDKey], v.md[CorrelationIDKey]
+		}
+	}
+	return c
+}
+
+var (
+	// messageIDPrefix makes message IDs unique across processes.
+	messageIDPrefix = newMessageIDPrefix()
+	messageIDSeq    uint64
+)
+
+func newMessageIDPrefix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic("mob: generate message ID prefix: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// formatMessageID formats a message ID of a given sequence number.
+func formatMessageID(seq uint64) string {
+	return messageIDPrefix + "-" + strconv.FormatUint(seq, 36)
+}
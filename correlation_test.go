@@ -0,0 +1,175 @@
+package mob
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+// withoutIDs returns a copy of given metadata without IDs assigned on dispatch, nil if nothing remains.
+func withoutIDs(md Metadata) Metadata {
+	cp := make(Metadata, len(md))
+	for k, v := range md {
+		switch k {
+		case MessageIDKey, CausationIDKey, CorrelationIDKey:
+		default:
+			cp[k] = v
+		}
+	}
+	if len(cp) == 0 {
+		return nil
+	}
+	return cp
+}
+
+// ids are IDs of a dispatched message.
+type ids struct {
+	message, causation, correlation string
+}
+
+func idsFrom(ctx context.Context) ids {
+	return ids{message: MessageID(ctx), causation: CausationID(ctx), correlation: CorrelationID(ctx)}
+}
+
+func TestCorrelation_NestedDispatch(t *testing.T) {
+	m := New()
+	var req, ev, nested ids
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		req = idsFrom(ctx)
+		return DummyResponse1{}, NewEventNotifier[DummyEvent1](m).Notify(ctx, DummyEvent1{})
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+		ev = idsFrom(ctx)
+		_, err := NewRequestSender[DummyRequest2, DummyResponse1](m).Send(ctx, DummyRequest2{})
+		return err
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	var nhf RequestHandlerFunc[DummyRequest2, DummyResponse1] = func(ctx context.Context, _ DummyRequest2) (DummyResponse1, error) {
+		nested = idsFrom(ctx)
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest2, DummyResponse1](m, nhf); err != nil {
+		t.Fatalf("register nested request handler: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(ctx, DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if req.message == "" || ev.message == "" || nested.message == "" {
+		t.Fatalf("want message IDs assigned, got %v, %v, %v", req, ev, nested)
+	}
+	if req.message == ev.message || ev.message == nested.message {
+		t.Errorf("want unique message IDs, got %v, %v, %v", req, ev, nested)
+	}
+	if want := (ids{message: req.message, correlation: req.message}); req != want {
+		t.Errorf("want root request's IDs %v, got %v", want, req)
+	}
+	if want := (ids{message: ev.message, causation: req.message, correlation: req.message}); ev != want {
+		t.Errorf("want event's IDs %v, got %v", want, ev)
+	}
+	if want := (ids{message: nested.message, causation: ev.message, correlation: req.message}); nested != want {
+		t.Errorf("want nested request's IDs %v, got %v", want, nested)
+	}
+	if got := MessageID(ctx); got != "" {
+		t.Errorf("want caller's context untouched, got message ID %s", got)
+	}
+}
+
+func TestCorrelation_CallerID(t *testing.T) {
+	m := New()
+	var got ids
+	var intercepted string
+	AddEventInterceptorTo(m, func(ctx context.Context, event interface{}, invoker NotifyInvoker) error {
+		intercepted = MessageID(ctx)
+		return invoker(ctx, event)
+	})
+	var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+		got = idsFrom(ctx)
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	ctx := WithMetadata(context.Background(), CorrelationIDKey, "request-997")
+	if err := NewEventNotifier[DummyEvent1](m).Notify(ctx, DummyEvent1{}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if got.correlation != "request-997" {
+		t.Errorf("want correlation ID request-997, got %s", got.correlation)
+	}
+	if got.causation != "" {
+		t.Errorf("want no causation ID, got %s", got.causation)
+	}
+	if got.message == "" || intercepted != got.message {
+		t.Errorf("want message ID %s seen by interceptor, got %s", got.message, intercepted)
+	}
+}
+
+func TestCorrelation_Remote(t *testing.T) {
+	remote := New()
+	got := make(chan ids, 1)
+	var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+		got <- idsFrom(ctx)
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](remote, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	srv := httptest.NewServer(NewHTTPHandler(remote, HTTPOptions{AcceptMetadata: AcceptMetadataKeys(MessageIDKey, CausationIDKey, CorrelationIDKey)}))
+	defer srv.Close()
+
+	m := New()
+	nf := NewRemoteEventNotifier[DummyEvent1](m, Remote{URL: srv.URL, Client: srv.Client()})
+	var req ids
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		req = idsFrom(ctx)
+		return DummyResponse1{}, nf.Notify(ctx, DummyEvent1{})
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	ev := <-got
+	if ev.message == "" || ev.message == req.message {
+		t.Errorf("want new message ID, got %s", ev.message)
+	}
+	if ev.causation != req.message || ev.correlation != req.message {
+		t.Errorf("want event caused by and correlated with %s, got %v", req.message, ev)
+	}
+}
+
+func TestCorrelation_TransportedIDs(t *testing.T) {
+	m := New()
+	var got ids
+	var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+		got = idsFrom(ctx)
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		// Redriven or replayed events carry IDs they were originally dispatched with.
+		ctx = withMetadata(ctx, Metadata{MessageIDKey: "original", CorrelationIDKey: "flow"})
+		return DummyResponse1{}, NewEventNotifier[DummyEvent1](m).Notify(ctx, DummyEvent1{})
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got.causation != "original" || got.correlation != "flow" || got.message == "" || got.message == "original" {
+		t.Errorf("want event caused by the transported message, got %v", got)
+	}
+	if md := MetadataFrom(context.Background()); md != nil {
+		t.Errorf("want no metadata, got %v", md)
+	}
+}
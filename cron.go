@@ -0,0 +1,164 @@
+package mob
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Schedule determines activation times of a recurring notification.
+type Schedule interface {
+	// Next returns the first activation time after a given time. A zero time, or a time which isn't
+	// after the given one, stops a recurring notification.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule activated every given interval. The interval must be positive,
+// NotifyRecurring returns ErrInvalidOption otherwise.
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+// A cron is a Schedule defined by a cron expression. Each field is a bit set of allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// anyDom and anyDow are set if the respective field is a wildcard.
+	anyDom, anyDow bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron returns a Schedule defined by a standard, five fields cron expression:
+// minute, hour, day of month, month and day of week. Each field supports wildcards (*),
+// ranges (1-5), steps (*/15, 1-30/2) and lists (1,15,30). Day of week 7 is Sunday, as is 0.
+// Predefined schedules like @hourly, @daily, @weekly, @monthly and @yearly are supported as well.
+//
+// Activation times are computed in the location of the time passed to Next.
+func ParseCron(expr string) (Schedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("mob: cron expression %q: want 5 fields, got %d", expr, len(fields))
+	}
+	var c cron
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("mob: cron expression %q: minute: %w", expr, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("mob: cron expression %q: hour: %w", expr, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("mob: cron expression %q: day of month: %w", expr, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("mob: cron expression %q: month: %w", expr, err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("mob: cron expression %q: day of week: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDom = strings.HasPrefix(fields[2], "*")
+	c.anyDow = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := min, max, 1
+		rng := part
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = s
+			rng = part[:i]
+		}
+		if rng != "*" {
+			var err error
+			if i := strings.IndexByte(rng, '-'); i >= 0 {
+				if lo, err = strconv.Atoi(rng[:i]); err != nil {
+					return 0, fmt.Errorf("invalid range in %q", part)
+				}
+				if hi, err = strconv.Atoi(rng[i+1:]); err != nil {
+					return 0, fmt.Errorf("invalid range in %q", part)
+				}
+			} else {
+				if lo, err = strconv.Atoi(rng); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+				// A single value with a step, e.g. 5/15, means a range up to the maximum.
+				hi = lo
+				if step > 1 {
+					hi = max
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range [%d, %d]", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Give up if there is no activation within the next five years, e.g. for 30th of February.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay follows the cron convention: if both day of month and day of week are restricted,
+// a day matches if either of them matches.
+func (c *cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDom || c.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
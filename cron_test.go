@@ -0,0 +1,86 @@
+package mob
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	// Saturday.
+	from := time.Date(2022, 1, 1, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want []time.Time
+	}{
+		{
+			expr: "* * * * *",
+			want: []time.Time{time.Date(2022, 1, 1, 10, 31, 0, 0, time.UTC), time.Date(2022, 1, 1, 10, 32, 0, 0, time.UTC)},
+		},
+		{
+			expr: "*/5 * * * *",
+			want: []time.Time{time.Date(2022, 1, 1, 10, 35, 0, 0, time.UTC), time.Date(2022, 1, 1, 10, 40, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 9-17/4 * * *",
+			want: []time.Time{time.Date(2022, 1, 1, 13, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 17, 0, 0, 0, time.UTC), time.Date(2022, 1, 2, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "15,45 8 * * 1-5",
+			want: []time.Time{time.Date(2022, 1, 3, 8, 15, 0, 0, time.UTC), time.Date(2022, 1, 3, 8, 45, 0, 0, time.UTC), time.Date(2022, 1, 4, 8, 15, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 1 * 7",
+			want: []time.Time{time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 29 2 *",
+			want: []time.Time{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "@monthly",
+			want: []time.Time{time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got := from
+			for _, want := range tt.want {
+				got = s.Next(got)
+				if !got.Equal(want) {
+					t.Fatalf("want %v, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err == nil {
+				t.Errorf("want err, got nil")
+			}
+		})
+	}
+}
+
+func TestParseCron_Never(t *testing.T) {
+	s, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("want zero time, got %v", got)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	from := time.Date(2022, 1, 1, 10, 30, 15, 0, time.UTC)
+	if got := Every(5 * time.Minute).Next(from); !got.Equal(from.Add(5 * time.Minute)) {
+		t.Errorf("want %v, got %v", from.Add(5*time.Minute), got)
+	}
+}
@@ -0,0 +1,317 @@
+package mob
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// A DeadLetter is an event a handler failed to handle.
+type DeadLetter struct {
+	// Event is a failed event.
+	Event interface{}
+	// EventType is a name of the event's type.
+	EventType string
+	// Handler is a name of a failed handler, empty if the handler is not named.
+	Handler string
+	// Err is a message of an error returned by the handler.
+	Err string
+	// Attempts is a number of attempts made to handle the event.
+	Attempts int
+	// Time is the time the event is dead-lettered at.
+	Time time.Time
+	// Metadata is metadata of the event's context.
+	Metadata Metadata
+}
+
+// DeadLetterQueue provides an interface for a storage of dead letters.
+type DeadLetterQueue interface {
+	// Put adds a dead letter to the queue.
+	Put(ctx context.Context, dl DeadLetter) error
+	// Drain removes all dead letters from the queue and returns them. Along with an error, it may return
+	// the dead letters which are removed despite the error.
+	Drain(ctx context.Context) ([]DeadLetter, error)
+}
+
+// SetDeadLetterQueueTo sets a DeadLetterQueue of the given Mob instance. An event a handler fails to handle
+// (after retries, if configured) is put to the queue. The error is still returned to the Notify caller.
+// Errors returned by the queue are passed to the Mob's error handler.
+func SetDeadLetterQueueTo(m *Mob, q DeadLetterQueue) {
+	m.dlq = q
+}
+
+// SetDeadLetterQueue sets a DeadLetterQueue of the global Mob instance. An event a handler fails to handle
+// (after retries, if configured) is put to the queue. The error is still returned to the Notify caller.
+// Errors returned by the queue are passed to the Mob's error handler.
+func SetDeadLetterQueue(q DeadLetterQueue) {
+	SetDeadLetterQueueTo(m, q)
+}
+
+// A noDeadLetterKey is a context key marking events which mustn't be dead-lettered,
+// e.g. because they're going to be redelivered.
+type noDeadLetterKey struct{}
+
+func withoutDeadLetters(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDeadLetterKey{}, true)
+}
+
+// A redriveKey is a context key of a DeadLetterQueue events are redriven from.
+type redriveKey struct{}
+
+// deadLetter puts a failed event to the queue it's redriven from, if any, or the Mob's dead letter queue, if any.
+func deadLetter(ctx context.Context, m *Mob, hn *handler, event interface{}, err error) {
+	if ctx.Value(noDeadLetterKey{}) != nil {
+		return
+	}
+	q := m.dlq
+	if rq, ok := ctx.Value(redriveKey{}).(DeadLetterQueue); ok {
+		q = rq
+	}
+	if q == nil {
+		return
+	}
+	dl := DeadLetter{
+		Event:     event,
+		EventType: TypeNameTo(m, event),
+		Handler:   hn.name,
+		Err:       err.Error(),
+		Attempts:  1,
+		Time:      m.clock.Now(),
+		Metadata:  MetadataFrom(ctx),
+	}
+	var aerr *attemptsError
+	if errors.As(err, &aerr) {
+		dl.Attempts = aerr.attempts
+	}
+	if err := q.Put(ctx, dl); err != nil {
+		handleError(ctx, m, fmt.Errorf("mob: dead letter: %w", err))
+	}
+}
+
+// RedriveTo drains a given DeadLetterQueue and notifies the dead-lettered events to the given Mob instance.
+// An event is handled only by the handlers named like the failed one, so handlers which succeeded don't handle
+// it again. An event failed by a handler which isn't named is handled by all handlers registered with its type.
+// Events are notified with a context carrying their metadata. Events failing again are put back to the given
+// queue, regardless of the Mob instance's queue. So are events that can't be notified at all, e.g. because
+// no such handler is registered.
+//
+// Dead letters are removed from the queue before they're redriven. If the process crashes while redriving,
+// the drained dead letters which aren't handled or put back yet are lost.
+//
+// Returns the number of redriven events and an error, if any. An error returned by the queue, including
+// an error of Drain returning some of the dead letters, doesn't stop redriving the remaining events.
+func RedriveTo(ctx context.Context, m *Mob, q DeadLetterQueue) (int, error) {
+	dls, err := q.Drain(ctx)
+	var aggr AggregateHandlerError
+	if err != nil {
+		aggr = append(aggr, err)
+	}
+	var n int
+	rctx := context.WithValue(ctx, redriveKey{}, q)
+	for _, dl := range dls {
+		err := notifyHandler(withMetadata(rctx, dl.Metadata), m, dl.Event, dl.Handler)
+		if errors.Is(err, ErrHandlerNotFound) {
+			if err := q.Put(ctx, dl); err != nil {
+				aggr = append(aggr, fmt.Errorf("mob: dead letter: %w", err))
+			}
+			aggr = append(aggr, fmt.Errorf("%s: %w", dl.EventType, err))
+			continue
+		}
+		n++
+		if err != nil {
+			aggr = append(aggr, err)
+		}
+	}
+	if len(aggr) > 0 {
+		return n, aggr
+	}
+	return n, nil
+}
+
+// Redrive drains a given DeadLetterQueue and notifies the dead-lettered events to the global Mob instance.
+//
+// Returns the number of redriven events and an error, if any.
+func Redrive(ctx context.Context, q DeadLetterQueue) (int, error) {
+	return RedriveTo(ctx, m, q)
+}
+
+// A MemoryDeadLetterQueue is an in-memory DeadLetterQueue.
+type MemoryDeadLetterQueue struct {
+	mu  sync.Mutex
+	dls []DeadLetter
+}
+
+// NewMemoryDeadLetterQueue returns an empty MemoryDeadLetterQueue.
+func NewMemoryDeadLetterQueue() *MemoryDeadLetterQueue {
+	return &MemoryDeadLetterQueue{}
+}
+
+// Put adds a dead letter to the queue.
+func (q *MemoryDeadLetterQueue) Put(_ context.Context, dl DeadLetter) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dls = append(q.dls, dl)
+	return nil
+}
+
+// Drain removes all dead letters from the queue and returns them.
+func (q *MemoryDeadLetterQueue) Drain(_ context.Context) ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dls := q.dls
+	q.dls = nil
+	return dls, nil
+}
+
+// Len returns the number of dead letters in the queue.
+func (q *MemoryDeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.dls)
+}
+
+// A FileDeadLetterQueue is a DeadLetterQueue backed by a file. Dead letters are appended to the file
+// as JSON lines, events are encoded as JSON. Dead letters which can't be decoded are kept in the file.
+type FileDeadLetterQueue struct {
+	m    *Mob
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDeadLetterQueue returns a FileDeadLetterQueue backed by a file at a given path.
+// The file is created if it doesn't exist. Drained events are decoded into types registered
+// in a given Mob instance's type registry.
+func NewFileDeadLetterQueue(m *Mob, path string) (*FileDeadLetterQueue, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return &FileDeadLetterQueue{m: m, path: path}, nil
+}
+
+// A fileDeadLetter is a DeadLetter as stored in a file.
+type fileDeadLetter struct {
+	Event     json.RawMessage `json:"event"`
+	EventType string          `json:"event_type"`
+	Handler   string          `json:"handler,omitempty"`
+	Err       string          `json:"error"`
+	Attempts  int             `json:"attempts"`
+	Time      time.Time       `json:"time"`
+	Metadata  Metadata        `json:"metadata,omitempty"`
+}
+
+// Put appends a dead letter to the file.
+func (q *FileDeadLetterQueue) Put(_ context.Context, dl DeadLetter) error {
+	ev, err := json.Marshal(dl.Event)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(fileDeadLetter{
+		Event:     ev,
+		EventType: dl.EventType,
+		Handler:   dl.Handler,
+		Err:       dl.Err,
+		Attempts:  dl.Attempts,
+		Time:      dl.Time,
+		Metadata:  dl.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	f, err := os.OpenFile(q.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Drain reads all dead letters from the file and removes them from it.
+// If an event can't be decoded, e.g. because its type is not registered, its dead letter is kept in the file,
+// so it can be drained once it's decodable. The remaining dead letters are returned along with an error.
+func (q *FileDeadLetterQueue) Drain(_ context.Context) ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	f, err := os.Open(q.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var dls []DeadLetter
+	var kept [][]byte
+	var derr error
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 16<<20)
+	for s.Scan() {
+		var fdl fileDeadLetter
+		if err := json.Unmarshal(s.Bytes(), &fdl); err != nil {
+			kept = append(kept, append([]byte(nil), s.Bytes()...))
+			derr = fmt.Errorf("mob: malformed dead letter: %w", err)
+			continue
+		}
+		ev, err := DecodeTo(q.m, JSONCodec{}, fdl.EventType, fdl.Event)
+		if err != nil {
+			kept = append(kept, append([]byte(nil), s.Bytes()...))
+			derr = err
+			continue
+		}
+		dls = append(dls, DeadLetter{
+			Event:     ev,
+			EventType: fdl.EventType,
+			Handler:   fdl.Handler,
+			Err:       fdl.Err,
+			Attempts:  fdl.Attempts,
+			Time:      fdl.Time,
+			Metadata:  fdl.Metadata,
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(kept) == 0 {
+		if err := os.Truncate(q.path, 0); err != nil {
+			return nil, err
+		}
+		return dls, nil
+	}
+	if err := q.keep(kept); err != nil {
+		return nil, err
+	}
+	return dls, fmt.Errorf("mob: %d dead letters kept: %w", len(kept), derr)
+}
+
+// keep replaces the file's content with given lines. The lines are written to a temporary file first,
+// so the file is left intact if they can't be written.
+func (q *FileDeadLetterQueue) keep(lines [][]byte) error {
+	tmp := q.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
@@ -0,0 +1,241 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotify_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name string
+		q    func(t *testing.T, m *Mob) DeadLetterQueue
+	}{
+		{
+			name: "memory",
+			q: func(*testing.T, *Mob) DeadLetterQueue {
+				return NewMemoryDeadLetterQueue()
+			},
+		},
+		{
+			name: "file",
+			q: func(t *testing.T, m *Mob) DeadLetterQueue {
+				q, err := NewFileDeadLetterQueue(m, filepath.Join(t.TempDir(), "dlq.jsonl"))
+				if err != nil {
+					t.Fatalf("new file dead letter queue: %v", err)
+				}
+				return q
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			SetClockTo(m, NewManualClock(now))
+			q := tt.q(t, m)
+			SetDeadLetterQueueTo(m, q)
+			fail := true
+			hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error {
+				if fail {
+					return errors.New("dummy")
+				}
+				return nil
+			}}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithName("Failing"), WithRetry(2, 0)); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			ev := DummyEvent1{String: "string", Int: 997}
+			if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), ev); err == nil {
+				t.Fatal("want err, got nil")
+			}
+			dls, err := q.Drain(context.Background())
+			if err != nil {
+				t.Fatalf("drain: %v", err)
+			}
+			if len(dls) != 1 {
+				t.Fatalf("want 1 dead letter, got %d", len(dls))
+			}
+			want := DeadLetter{
+				Event:     ev,
+				EventType: "github.com/erni27/mob.DummyEvent1",
+				Handler:   "Failing",
+				Err:       "dummy",
+				Attempts:  2,
+				Time:      now,
+			}
+			got := dls[0]
+			got.Metadata = withoutIDs(got.Metadata)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("want %v, got %v", want, got)
+			}
+			// Put the dead letter back and redrive it.
+			if err := q.Put(context.Background(), dls[0]); err != nil {
+				t.Fatalf("put: %v", err)
+			}
+			fail = false
+			n, err := RedriveTo(context.Background(), m, q)
+			if err != nil {
+				t.Fatalf("want success, got %v", err)
+			}
+			if n != 1 {
+				t.Errorf("want 1 redriven event, got %d", n)
+			}
+			if calls := hn.Calls(); calls != 3 {
+				t.Errorf("want handler called exactly 3, got %d", calls)
+			}
+			if dls, _ := q.Drain(context.Background()); len(dls) != 0 {
+				t.Errorf("want queue drained, got %v", dls)
+			}
+		})
+	}
+}
+
+func TestRedrive_HandlerNotFound(t *testing.T) {
+	defer clear()
+	q := NewMemoryDeadLetterQueue()
+	if err := q.Put(context.Background(), DeadLetter{Event: DummyEvent1{}}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	n, err := Redrive(context.Background(), q)
+	if !errors.Is(err, ErrHandlerNotFound) {
+		t.Errorf("want err %v, got %v", ErrHandlerNotFound, err)
+	}
+	if n != 0 {
+		t.Errorf("want no redriven events, got %d", n)
+	}
+	if q.Len() != 1 {
+		t.Errorf("want dead letter put back, got %d", q.Len())
+	}
+}
+
+func TestRedrive_FailedHandlerOnly(t *testing.T) {
+	m := New()
+	q := NewMemoryDeadLetterQueue()
+	SetDeadLetterQueueTo(m, q)
+	fail := true
+	failing := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error {
+		if fail {
+			return errors.New("dummy")
+		}
+		return nil
+	}}
+	succeeding := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, failing, WithName("Failing")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, succeeding, WithName("Succeeding")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err == nil {
+		t.Fatal("want err, got nil")
+	}
+	fail = false
+	if n, err := RedriveTo(context.Background(), m, q); err != nil || n != 1 {
+		t.Fatalf("want 1 redriven event, got %d and err %v", n, err)
+	}
+	if failing.Calls() != 2 || succeeding.Calls() != 1 {
+		t.Errorf("want only the failed handler called again, got %d and %d calls", failing.Calls(), succeeding.Calls())
+	}
+	// A dead letter of a handler which is no longer registered is put back.
+	if err := q.Put(context.Background(), DeadLetter{Event: DummyEvent1{}, Handler: "Removed"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := RedriveTo(context.Background(), m, q); !errors.Is(err, ErrHandlerNotFound) {
+		t.Errorf("want err %v, got %v", ErrHandlerNotFound, err)
+	}
+	if q.Len() != 1 {
+		t.Errorf("want dead letter put back, got %d", q.Len())
+	}
+}
+
+// A failingPutQueue is a DeadLetterQueue failing to put dead letters back.
+type failingPutQueue struct {
+	dls []DeadLetter
+}
+
+func (q *failingPutQueue) Put(context.Context, DeadLetter) error {
+	return errors.New("dummy")
+}
+
+func (q *failingPutQueue) Drain(context.Context) ([]DeadLetter, error) {
+	dls := q.dls
+	q.dls = nil
+	return dls, nil
+}
+
+func TestRedrive_PutError(t *testing.T) {
+	m := New()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	q := &failingPutQueue{dls: []DeadLetter{{Event: DummyRequest1{}}, {Event: DummyEvent1{}}}}
+	n, err := RedriveTo(context.Background(), m, q)
+	if err == nil {
+		t.Error("want err, got nil")
+	}
+	if n != 1 || hn.Calls() != 1 {
+		t.Errorf("want remaining dead letters redriven, got %d redriven and %d calls", n, hn.Calls())
+	}
+}
+
+func TestRedrive_FailedAgain(t *testing.T) {
+	m := New()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithName("Failing")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	// The Mob instance has no dead letter queue, events failing again are put back to the redriven one.
+	q := NewMemoryDeadLetterQueue()
+	if err := q.Put(context.Background(), DeadLetter{Event: DummyEvent1{}, Handler: "Failing"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	n, err := RedriveTo(context.Background(), m, q)
+	if err == nil {
+		t.Error("want err, got nil")
+	}
+	if n != 1 {
+		t.Errorf("want 1 redriven event, got %d", n)
+	}
+	dls, _ := q.Drain(context.Background())
+	if len(dls) != 1 || dls[0].Handler != "Failing" || dls[0].Err != "dummy" {
+		t.Errorf("want dead letter put back, got %v", dls)
+	}
+}
+
+func TestFileDeadLetterQueue_UnknownType(t *testing.T) {
+	m := New()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	q, err := NewFileDeadLetterQueue(m, filepath.Join(t.TempDir(), "dlq.jsonl"))
+	if err != nil {
+		t.Fatalf("new file dead letter queue: %v", err)
+	}
+	if err := q.Put(context.Background(), DeadLetter{Event: DummyEvent1{}, EventType: "github.com/erni27/mob.Removed"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := q.Put(context.Background(), DeadLetter{Event: DummyEvent1{}, EventType: "github.com/erni27/mob.DummyEvent1"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	n, err := RedriveTo(context.Background(), m, q)
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("want err %v, got %v", ErrUnknownType, err)
+	}
+	if n != 1 || hn.Calls() != 1 {
+		t.Errorf("want decodable dead letter redriven, got %d redriven and %d calls", n, hn.Calls())
+	}
+	// The undecodable dead letter is kept.
+	dls, err := q.Drain(context.Background())
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("want err %v, got %v", ErrUnknownType, err)
+	}
+	if len(dls) != 0 {
+		t.Errorf("want no decodable dead letters, got %v", dls)
+	}
+}
@@ -0,0 +1,236 @@
+package mob
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// An Info describes a Mob instance's registry and live stats.
+type Info struct {
+	RequestHandlers   []RequestHandlerInfo `json:"request_handlers"`
+	GatherHandlers    []RequestHandlerInfo `json:"gather_handlers"`
+	EventTypes        []EventTypeInfo      `json:"event_types"`
+	Interceptors      []string             `json:"interceptors"`
+	EventInterceptors []string             `json:"event_interceptors"`
+	Stats             Stats                `json:"stats"`
+}
+
+// A RequestHandlerInfo describes a registered request or gather handler.
+type RequestHandlerInfo struct {
+	// Request and Response are names of the handler's request and response types in the Mob's type registry.
+	Request  string `json:"request"`
+	Response string `json:"response"`
+	// Name is a name of the handler, empty if the handler is unnamed.
+	Name string `json:"name,omitempty"`
+}
+
+// An EventTypeInfo describes an event type with registered handlers.
+type EventTypeInfo struct {
+	// Type is a name of the event type in the Mob's type registry.
+	Type string `json:"type"`
+	// Handlers is a number of the event type's handlers, including subscriptions and one-shot handlers.
+	Handlers int `json:"handlers"`
+	// Names are names of the event type's named handlers.
+	Names []string `json:"names,omitempty"`
+}
+
+// Stats are live stats of dispatches of a Mob instance. A dispatch is a single Send, Notify or Gather
+// for which handlers are found.
+type Stats struct {
+	// InFlight is a number of dispatches in progress.
+	InFlight int64 `json:"in_flight"`
+	// Dispatched is a number of finished dispatches.
+	Dispatched uint64 `json:"dispatched"`
+	// Errors is a number of finished dispatches which returned an error.
+	Errors uint64 `json:"errors"`
+}
+
+// dispatchStats count dispatches of a Mob instance. Counters are accessed atomically.
+type dispatchStats struct {
+	inFlight   int64
+	dispatched uint64
+	errors     uint64
+}
+
+func (s *dispatchStats) begin() {
+	atomic.AddInt64(&s.inFlight, 1)
+}
+
+func (s *dispatchStats) end(err error) {
+	atomic.AddInt64(&s.inFlight, -1)
+	atomic.AddUint64(&s.dispatched, 1)
+	if err != nil {
+		atomic.AddUint64(&s.errors, 1)
+	}
+}
+
+// InspectTo returns an Info describing the given Mob instance. Handlers and event types are sorted by names.
+func InspectTo(m *Mob) Info {
+	info := Info{
+		Interceptors:      make([]string, 0, len(m.interceptors)),
+		EventInterceptors: make([]string, 0, len(m.einterceptors)),
+		Stats: Stats{
+			InFlight:   atomic.LoadInt64(&m.stats.inFlight),
+			Dispatched: atomic.LoadUint64(&m.stats.dispatched),
+			Errors:     atomic.LoadUint64(&m.stats.errors),
+		},
+	}
+	for _, i := range m.interceptors {
+		info.Interceptors = append(info.Interceptors, funcName(i))
+	}
+	for _, i := range m.einterceptors {
+		info.EventInterceptors = append(info.EventInterceptors, funcName(i))
+	}
+	m.mu.RLock()
+	name := func(t reflect.Type) string {
+		if n, ok := m.names[t]; ok {
+			return n
+		}
+		return typeName(t)
+	}
+	info.RequestHandlers = make([]RequestHandlerInfo, 0, len(m.rhandlers))
+	for k, hn := range m.rhandlers {
+		info.RequestHandlers = append(info.RequestHandlers, RequestHandlerInfo{Request: name(k.reqt), Response: name(k.rest), Name: hn.name})
+	}
+	info.GatherHandlers = make([]RequestHandlerInfo, 0, len(m.ghandlers))
+	for k, hns := range m.ghandlers {
+		for _, hn := range hns {
+			info.GatherHandlers = append(info.GatherHandlers, RequestHandlerInfo{Request: name(k.reqt), Response: name(k.rest), Name: hn.name})
+		}
+	}
+	info.EventTypes = make([]EventTypeInfo, 0, len(m.ehandlers))
+	for t, hns := range m.ehandlers {
+		et := EventTypeInfo{Type: name(t), Handlers: len(hns)}
+		for _, hn := range hns {
+			if hn.name != "" {
+				et.Names = append(et.Names, hn.name)
+			}
+		}
+		sort.Strings(et.Names)
+		info.EventTypes = append(info.EventTypes, et)
+	}
+	m.mu.RUnlock()
+	sortRequestHandlerInfos(info.RequestHandlers)
+	sortRequestHandlerInfos(info.GatherHandlers)
+	sort.Slice(info.EventTypes, func(i, j int) bool { return info.EventTypes[i].Type < info.EventTypes[j].Type })
+	return info
+}
+
+// Inspect returns an Info describing the global Mob instance.
+func Inspect() Info {
+	return InspectTo(m)
+}
+
+func sortRequestHandlerInfos(infos []RequestHandlerInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Request != infos[j].Request {
+			return infos[i].Request < infos[j].Request
+		}
+		if infos[i].Response != infos[j].Response {
+			return infos[i].Response < infos[j].Response
+		}
+		return infos[i].Name < infos[j].Name
+	})
+}
+
+// funcName returns a name of a given function, e.g. an interceptor.
+func funcName(f interface{}) string {
+	rf := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if rf == nil {
+		return "unknown"
+	}
+	return rf.Name()
+}
+
+// expvarMu serializes checking and publishing expvar variables, as expvar.Publish panics on a taken name.
+var expvarMu sync.Mutex
+
+// PublishExpvarTo publishes an Info describing the given Mob instance as an expvar variable of a given name.
+// The Info is refreshed each time the variable is read. Returns an error if the name is already in use.
+func PublishExpvarTo(m *Mob, name string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("mob: expvar %s already published", name)
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return InspectTo(m)
+	}))
+	return nil
+}
+
+// PublishExpvar publishes an Info describing the global Mob instance as an expvar variable of a given name.
+func PublishExpvar(name string) error {
+	return PublishExpvarTo(m, name)
+}
+
+// NewDebugHandler returns an http.Handler serving a page describing the given Mob instance,
+// e.g. under /debug/mob. The page is an HTML document, or a JSON encoded Info
+// if the format=json query parameter is set or JSON is accepted by the client.
+func NewDebugHandler(m *Mob) http.Handler {
+	return &debugHandler{m: m}
+}
+
+type debugHandler struct {
+	m *Mob
+}
+
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	info := InspectTo(h.m)
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(info)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = debugPage.Execute(w, info)
+}
+
+var debugPage = template.Must(template.New("mob").Parse(`<!DOCTYPE html>
+<html>
+<head><title>mob</title></head>
+<body>
+<h1>mob</h1>
+<h2>Stats</h2>
+<table>
+<tr><th>In flight</th><td>{{.Stats.InFlight}}</td></tr>
+<tr><th>Dispatched</th><td>{{.Stats.Dispatched}}</td></tr>
+<tr><th>Errors</th><td>{{.Stats.Errors}}</td></tr>
+</table>
+<h2>Request handlers</h2>
+<table>
+<tr><th>Request</th><th>Response</th><th>Name</th></tr>
+{{range .RequestHandlers}}<tr><td>{{.Request}}</td><td>{{.Response}}</td><td>{{.Name}}</td></tr>
+{{end}}</table>
+<h2>Gather handlers</h2>
+<table>
+<tr><th>Request</th><th>Response</th><th>Name</th></tr>
+{{range .GatherHandlers}}<tr><td>{{.Request}}</td><td>{{.Response}}</td><td>{{.Name}}</td></tr>
+{{end}}</table>
+<h2>Event types</h2>
+<table>
+<tr><th>Type</th><th>Handlers</th><th>Names</th></tr>
+{{range .EventTypes}}<tr><td>{{.Type}}</td><td>{{.Handlers}}</td><td>{{range $i, $n := .Names}}{{if $i}}, {{end}}{{$n}}{{end}}</td></tr>
+{{end}}</table>
+<h2>Interceptors</h2>
+<ol>
+{{range .Interceptors}}<li>{{.}}</li>
+{{end}}</ol>
+<h2>Event interceptors</h2>
+<ol>
+{{range .EventInterceptors}}<li>{{.}}</li>
+{{end}}</ol>
+</body>
+</html>
+`))
@@ -0,0 +1,172 @@
+package mob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDebugMob(t *testing.T) *Mob {
+	m := New()
+	if err := RegisterRequestHandlerTo[DummyRequest2, DummyResponse1](m, DummyRequestHandler4{}, WithName("Dummy")); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if err := RegisterGatherHandlerTo[DummyRequest2, DummyResponse1](m, DummyRequestHandler4{}); err != nil {
+		t.Fatalf("register gather handler: %v", err)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}, WithName("B")); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}, WithName("A")); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	AddInterceptorTo(m, CollectEventsTo(m))
+	return m
+}
+
+func TestInspect(t *testing.T) {
+	m := newTestDebugMob(t)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error {
+		close(started)
+		<-block
+		return errors.New("dummy")
+	}}
+	var ehf EventHandlerFunc[DummyRequest1] = func(context.Context, DummyRequest1) error { return nil }
+	if err := RegisterEventHandlerTo[DummyRequest1](m, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest2, DummyResponse1](m).Send(context.Background(), DummyRequest2{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	failing := New()
+	if err := RegisterEventHandlerTo[DummyEvent1](failing, hn); err != nil {
+		t.Fatalf("register failing handler: %v", err)
+	}
+	done := make(chan error)
+	go func() { done <- NewEventNotifier[DummyEvent1](failing).Notify(context.Background(), DummyEvent1{}) }()
+	<-started
+	if want, got := (Stats{InFlight: 1}), InspectTo(failing).Stats; got != want {
+		t.Errorf("want stats %v while notifying, got %v", want, got)
+	}
+	close(block)
+	if err := <-done; err == nil {
+		t.Fatal("want err, got nil")
+	}
+	if want, got := (Stats{Dispatched: 1, Errors: 1}), InspectTo(failing).Stats; got != want {
+		t.Errorf("want stats %v, got %v", want, got)
+	}
+
+	info := InspectTo(m)
+	want := Info{
+		RequestHandlers: []RequestHandlerInfo{{Request: "github.com/erni27/mob.DummyRequest2", Response: "github.com/erni27/mob.DummyResponse1", Name: "Dummy"}},
+		GatherHandlers:  []RequestHandlerInfo{{Request: "github.com/erni27/mob.DummyRequest2", Response: "github.com/erni27/mob.DummyResponse1"}},
+		EventTypes: []EventTypeInfo{
+			{Type: "github.com/erni27/mob.DummyEvent1", Handlers: 3, Names: []string{"A", "B"}},
+			{Type: "github.com/erni27/mob.DummyRequest1", Handlers: 1},
+		},
+		Interceptors:      []string{"github.com/erni27/mob.CollectEventsTo.func1"},
+		EventInterceptors: []string{},
+		Stats:             Stats{Dispatched: 1},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("want %+v, got %+v", want, info)
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	srv := httptest.NewServer(NewDebugHandler(newTestDebugMob(t)))
+	defer srv.Close()
+	get := func(path string) (string, string) {
+		res, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		return res.Header.Get("Content-Type"), string(body)
+	}
+	ct, body := get("/debug/mob")
+	if !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("want HTML, got %s", ct)
+	}
+	for _, s := range []string{"github.com/erni27/mob.DummyRequest2", "<td>A, B</td>", "CollectEventsTo"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("want page containing %q, got %s", s, body)
+		}
+	}
+	ct, body = get("/debug/mob?format=json")
+	if ct != "application/json" {
+		t.Errorf("want JSON, got %s", ct)
+	}
+	var info Info
+	if err := json.Unmarshal([]byte(body), &info); err != nil {
+		t.Fatalf("decode info: %v", err)
+	}
+	if len(info.EventTypes) != 1 || info.EventTypes[0].Handlers != 3 {
+		t.Errorf("want event types described, got %v", info.EventTypes)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	defer clear()
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	// Unique, as expvar variables can't be unpublished.
+	name := fmt.Sprintf("mob_test_%d", time.Now().UnixNano())
+	if err := PublishExpvar(name); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := PublishExpvar(name); err == nil {
+		t.Error("want err publishing a name twice, got nil")
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	var info Info
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &info); err != nil {
+		t.Fatalf("decode info: %v", err)
+	}
+	if info.Stats.Dispatched != 1 || len(info.EventTypes) != 1 {
+		t.Errorf("want live info published, got %+v", info)
+	}
+}
+
+func TestPublishExpvar_Concurrent(t *testing.T) {
+	m := New()
+	name := fmt.Sprintf("mob_test_concurrent_%d", time.Now().UnixNano())
+	const n = 10
+	var published int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := PublishExpvarTo(m, name); err == nil {
+				atomic.AddInt32(&published, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if published != 1 {
+		t.Errorf("want name published exactly once, got %d", published)
+	}
+}
@@ -0,0 +1,290 @@
+package mob
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// A StoredEvent is an event recorded in an EventStore.
+type StoredEvent struct {
+	// Position is a position of the event in the store, starting from 1. It's assigned by the store.
+	Position uint64
+	// Event is a recorded event.
+	Event interface{}
+	// EventType is a name of the event's type.
+	EventType string
+	// Time is the time the event is notified at.
+	Time time.Time
+	// Metadata is data recorded along with the event.
+	Metadata Metadata
+}
+
+// EventStore provides an interface for an append-only storage of events.
+type EventStore interface {
+	// Append appends an event to the store and assigns it the next position.
+	Append(ctx context.Context, e StoredEvent) error
+	// Read calls f for each stored event, starting from a given position, in order they're appended.
+	// Reading stops at the first error returned by f.
+	Read(ctx context.Context, from uint64, f func(e StoredEvent) error) error
+}
+
+// A replayKey is a context key marking replayed events.
+type replayKey struct{}
+
+// IsReplay reports whether a given context belongs to a replayed event.
+// Handlers can use it to skip side effects, such as sending emails, when read models are rebuilt.
+func IsReplay(ctx context.Context) bool {
+	_, ok := ctx.Value(replayKey{}).(bool)
+	return ok
+}
+
+// RecordEventsTo returns an EventInterceptor that appends each event notified to the given Mob instance
+// to a given EventStore before its handlers are executed. If the event can't be appended, its handlers aren't
+// executed and an error is returned. Metadata of the context is recorded along with an event.
+// Metadata, if not nil, returns additional data recorded along with an event.
+//
+// Replayed events are not recorded again.
+func RecordEventsTo(m *Mob, s EventStore, metadata func(ctx context.Context) Metadata) EventInterceptor {
+	return func(ctx context.Context, event interface{}, invoker NotifyInvoker) error {
+		if IsReplay(ctx) {
+			return invoker(ctx, event)
+		}
+		e := StoredEvent{
+			Event:     event,
+			EventType: TypeNameTo(m, event),
+			Time:      m.clock.Now(),
+			Metadata:  MetadataFrom(ctx),
+		}
+		if metadata != nil {
+			e.Metadata = MetadataFrom(withMetadata(ctx, metadata(ctx)))
+		}
+		if err := s.Append(ctx, e); err != nil {
+			return fmt.Errorf("mob: record event: %w", err)
+		}
+		return invoker(ctx, event)
+	}
+}
+
+// RecordEvents returns an EventInterceptor that appends each event notified to the global Mob instance
+// to a given EventStore before its handlers are executed.
+func RecordEvents(s EventStore, metadata func(ctx context.Context) Metadata) EventInterceptor {
+	return RecordEventsTo(m, s, metadata)
+}
+
+// A ReplayFilter selects stored events to replay. Zero values select all events.
+type ReplayFilter struct {
+	// From is a position of the first replayed event.
+	From uint64
+	// Since excludes events notified before a given time.
+	Since time.Time
+	// Until excludes events notified at or after a given time.
+	Until time.Time
+}
+
+func (f ReplayFilter) match(e StoredEvent) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ReplayTo notifies events of a given EventStore selected by a given filter to the given Mob instance again,
+// in order they're recorded. Events are notified with a context marked as a replay, see IsReplay,
+// and carrying metadata recorded along with them.
+// Events without registered handlers are skipped. Replaying stops at the first error.
+//
+// Returns the number of replayed events and an error, if any.
+func ReplayTo(ctx context.Context, m *Mob, s EventStore, f ReplayFilter) (int, error) {
+	ctx = context.WithValue(ctx, replayKey{}, true)
+	var n int
+	err := s.Read(ctx, f.From, func(e StoredEvent) error {
+		if !f.match(e) {
+			return nil
+		}
+		// Compared directly, so handlers' errors wrapping ErrHandlerNotFound aren't skipped.
+		if err := notifyAny(withMetadata(ctx, e.Metadata), m, e.Event); err != nil && err != ErrHandlerNotFound {
+			return fmt.Errorf("%s at %d: %w", e.EventType, e.Position, err)
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// Replay notifies events of a given EventStore selected by a given filter to the global Mob instance again.
+//
+// Returns the number of replayed events and an error, if any.
+func Replay(ctx context.Context, s EventStore, f ReplayFilter) (int, error) {
+	return ReplayTo(ctx, m, s, f)
+}
+
+// A FileEventStore is an EventStore backed by an append-only file. Events are stored as JSON lines,
+// their payloads are encoded by a Codec. An incomplete last line, left by an interrupted write, is ignored.
+type FileEventStore struct {
+	m    *Mob
+	path string
+	c    Codec
+	// mu guards f, last and size.
+	mu   sync.Mutex
+	f    *os.File
+	last uint64
+	// size is a size of the file's complete lines.
+	size int64
+}
+
+// NewFileEventStore returns a FileEventStore backed by a file at a given path, encoding events by a given Codec.
+// The file is created if it doesn't exist. Read events are decoded into types registered
+// in a given Mob instance's type registry. The store must be closed when it's no longer used.
+// An incomplete last line of the file is truncated.
+func NewFileEventStore(m *Mob, path string, c Codec) (*FileEventStore, error) {
+	s := &FileEventStore{m: m, path: path, c: c}
+	size, err := s.read(0, -1, func(fe fileStoredEvent) error {
+		s.last = fe.Position
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.f = f
+	s.size = size
+	return s, nil
+}
+
+// A fileStoredEvent is a StoredEvent as stored in a file.
+type fileStoredEvent struct {
+	Position  uint64    `json:"position"`
+	EventType string    `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+	Time      time.Time `json:"time"`
+	Metadata  Metadata  `json:"metadata,omitempty"`
+}
+
+// Append appends an event to the file and assigns it the next position.
+func (s *FileEventStore) Append(_ context.Context, e StoredEvent) error {
+	payload, err := s.c.Marshal(e.Event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return ErrClosed
+	}
+	line, err := json.Marshal(fileStoredEvent{
+		Position:  s.last + 1,
+		EventType: e.EventType,
+		Payload:   payload,
+		Time:      e.Time,
+		Metadata:  e.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := s.f.Write(line); err != nil {
+		// A partially written line is removed, otherwise the next line would be appended to it.
+		// If it can't be removed, the store is closed and the line is truncated when the file is opened again.
+		if terr := s.f.Truncate(s.size); terr != nil {
+			s.f.Close()
+			s.f = nil
+		}
+		return err
+	}
+	s.last++
+	s.size += int64(len(line))
+	return nil
+}
+
+// Read calls f for each stored event, starting from a given position, in order they're appended.
+// If an event can't be decoded, e.g. because its type is not registered, an error is returned.
+func (s *FileEventStore) Read(ctx context.Context, from uint64, f func(e StoredEvent) error) error {
+	// Events appended while reading are not read, the file is read only up to its current size.
+	s.mu.Lock()
+	var size int64 = -1
+	if s.f != nil {
+		size = s.size
+	}
+	s.mu.Unlock()
+	_, err := s.read(from, size, func(fe fileStoredEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ev, err := DecodeTo(s.m, s.c, fe.EventType, fe.Payload)
+		if err != nil {
+			return err
+		}
+		return f(StoredEvent{
+			Position:  fe.Position,
+			Event:     ev,
+			EventType: fe.EventType,
+			Time:      fe.Time,
+			Metadata:  fe.Metadata,
+		})
+	})
+	return err
+}
+
+// read calls f for each event stored within the first size bytes of the file, or the whole file if size is negative.
+// An incomplete last line is ignored. Returns a size of the read complete lines.
+func (s *FileEventStore) read(from uint64, size int64, f func(fe fileStoredEvent) error) (int64, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if size >= 0 {
+		r = io.LimitReader(file, size)
+	}
+	br := bufio.NewReader(r)
+	var n int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n += int64(len(line))
+		var fe fileStoredEvent
+		if err := json.Unmarshal(line, &fe); err != nil {
+			return n, fmt.Errorf("mob: malformed stored event: %w", err)
+		}
+		if fe.Position < from {
+			continue
+		}
+		if err := f(fe); err != nil {
+			return n, err
+		}
+	}
+}
+
+// Close closes the store's file. Events can't be appended once the store is closed.
+func (s *FileEventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,224 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordEvents_Replay(t *testing.T) {
+	m := New()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+	SetClockTo(m, c)
+	var got []DummyEvent1
+	var replayed []bool
+	var hf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, ev DummyEvent1) error {
+		got = append(got, ev)
+		replayed = append(replayed, IsReplay(ctx))
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	s, err := NewFileEventStore(m, path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("new file event store: %v", err)
+	}
+	defer s.Close()
+	AddEventInterceptorTo(m, RecordEventsTo(m, s, func(context.Context) Metadata {
+		return Metadata{"tenant": "acme"}
+	}))
+	nf := NewEventNotifier[DummyEvent1](m)
+	for i := 1; i <= 3; i++ {
+		if err := nf.Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+		c.Advance(time.Minute)
+	}
+	var stored []StoredEvent
+	if err := s.Read(context.Background(), 0, func(e StoredEvent) error {
+		e.Metadata = withoutIDs(e.Metadata)
+		stored = append(stored, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := StoredEvent{
+		Position:  2,
+		Event:     DummyEvent1{Int: 2},
+		EventType: "github.com/erni27/mob.DummyEvent1",
+		Time:      start.Add(time.Minute),
+		Metadata:  Metadata{"tenant": "acme"},
+	}
+	if len(stored) != 3 || !reflect.DeepEqual(stored[1], want) {
+		t.Fatalf("want second stored event %v, got %v", want, stored)
+	}
+
+	tests := []struct {
+		name string
+		f    ReplayFilter
+		want []DummyEvent1
+	}{
+		{
+			name: "all",
+			want: []DummyEvent1{{Int: 1}, {Int: 2}, {Int: 3}},
+		},
+		{
+			name: "from position",
+			f:    ReplayFilter{From: 3},
+			want: []DummyEvent1{{Int: 3}},
+		},
+		{
+			name: "time range",
+			f:    ReplayFilter{Since: start.Add(time.Minute), Until: start.Add(2 * time.Minute)},
+			want: []DummyEvent1{{Int: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, replayed = nil, nil
+			n, err := ReplayTo(context.Background(), m, s, tt.f)
+			if err != nil {
+				t.Fatalf("want success, got %v", err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("want %d replayed events, got %d", len(tt.want), n)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+			for _, r := range replayed {
+				if !r {
+					t.Error("want replayed event's context marked as replay")
+				}
+			}
+		})
+	}
+
+	var count int
+	if err := s.Read(context.Background(), 0, func(StoredEvent) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("want replayed events not recorded again, got %d stored events", count)
+	}
+}
+
+func TestFileEventStore_Reopen(t *testing.T) {
+	m := New()
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	s, err := NewFileEventStore(m, path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("new file event store: %v", err)
+	}
+	if err := s.Append(context.Background(), StoredEvent{Event: DummyEvent1{Int: 1}, EventType: "github.com/erni27/mob.DummyEvent1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.Append(context.Background(), StoredEvent{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+	s, err = NewFileEventStore(m, path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("reopen file event store: %v", err)
+	}
+	defer s.Close()
+	if err := s.Append(context.Background(), StoredEvent{Event: DummyEvent1{Int: 2}, EventType: "github.com/erni27/mob.DummyEvent1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	var positions []uint64
+	if err := s.Read(context.Background(), 0, func(e StoredEvent) error {
+		positions = append(positions, e.Position)
+		return nil
+	}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if want := []uint64{1, 2}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("want positions %v, got %v", want, positions)
+	}
+}
+
+func TestFileEventStore_TornLine(t *testing.T) {
+	m := New()
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	s, err := NewFileEventStore(m, path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("new file event store: %v", err)
+	}
+	if err := s.Append(context.Background(), StoredEvent{Event: DummyEvent1{Int: 1}, EventType: "github.com/erni27/mob.DummyEvent1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	s.Close()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open file: %v", err)
+	}
+	if _, err := f.WriteString(`{"position":2,"event_ty`); err != nil {
+		t.Fatalf("write torn line: %v", err)
+	}
+	f.Close()
+	var positions []uint64
+	read := func(s *FileEventStore) {
+		t.Helper()
+		positions = nil
+		if err := s.Read(context.Background(), 0, func(e StoredEvent) error {
+			positions = append(positions, e.Position)
+			return nil
+		}); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+	}
+	read(s)
+	if want := []uint64{1}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("want torn line ignored, got positions %v", positions)
+	}
+	s, err = NewFileEventStore(m, path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("reopen file event store: %v", err)
+	}
+	defer s.Close()
+	if err := s.Append(context.Background(), StoredEvent{Event: DummyEvent1{Int: 2}, EventType: "github.com/erni27/mob.DummyEvent1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	read(s)
+	if want := []uint64{1, 2}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("want positions %v, got %v", want, positions)
+	}
+}
+
+func TestRecordEvents_AppendError(t *testing.T) {
+	defer clear()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandler[DummyEvent1](hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	s, err := NewFileEventStore(m, filepath.Join(t.TempDir(), "events.jsonl"), JSONCodec{})
+	if err != nil {
+		t.Fatalf("new file event store: %v", err)
+	}
+	s.Close()
+	AddEventInterceptor(RecordEvents(s, nil))
+	if err := Notify(context.Background(), DummyEvent1{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+	if hn.Calls() != 0 {
+		t.Errorf("want handler not called, got %d calls", hn.Calls())
+	}
+}
@@ -12,14 +12,15 @@ func LoggingInterceptor(ctx context.Context, req interface{}, invoker mob.SendIn
 	log.Printf("Starting. Request: %v\n", req)
 	res, err := invoker(ctx, req)
 	if err != nil {
-		log.Printf("Error occured. Error: %v", err)
+		log.Printf("Error occurred. Error: %v\n", err)
+		return res, err
 	}
 	log.Printf("Ending. Response: %v\n", res)
 	return res, nil
 }
 
 func main() {
-	// Add LogginInterceptor to the global mob instance.
+	// Add LoggingInterceptor to the global mob instance.
 	mob.AddInterceptor(LoggingInterceptor)
 	// Register EchoRequestHandler to the global mob instance.
 	if err := mob.RegisterRequestHandler[examples.EchoRequest, examples.EchoResponse](examples.EchoRequestHandler{}); err != nil {
@@ -0,0 +1,117 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A Fallback configures a fallback request handler invoked when a primary handler fails.
+type Fallback[T any, U any] struct {
+	// Handler is a fallback request handler.
+	Handler RequestHandler[T, U]
+	// On lists errors the fallback is invoked on. If empty, the fallback is invoked on any error.
+	// Errors caused by the caller's context being canceled or exceeding its deadline never invoke the fallback.
+	On []error
+	// Timeout bounds an execution of a primary handler. If exceeded, the fallback is invoked.
+	// Zero means no timeout.
+	Timeout time.Duration
+	// Report is called when a primary handler failed and the fallback succeeded. Optional.
+	Report func(ctx context.Context, err *FallbackError)
+}
+
+// A FallbackError describes a failure of a primary handler and an outcome of its fallback.
+type FallbackError struct {
+	// Primary is an error returned by a primary handler.
+	Primary error
+	// Fallback is an error returned by a fallback handler, nil if the fallback succeeded.
+	Fallback error
+}
+
+func (e *FallbackError) Error() string {
+	if e.Fallback == nil {
+		return fmt.Sprintf("primary failed: %v, fallback succeeded", e.Primary)
+	}
+	return fmt.Sprintf("primary failed: %v, fallback failed: %v", e.Primary, e.Fallback)
+}
+
+func (e *FallbackError) Is(target error) bool {
+	return errors.Is(e.Primary, target) || (e.Fallback != nil && errors.Is(e.Fallback, target))
+}
+
+// A fallbackRequestHandler invokes a fallback handler when the embedded one fails.
+type fallbackRequestHandler[T any, U any] struct {
+	embedded RequestHandler[T, U]
+	fb       Fallback[T, U]
+}
+
+func (h *fallbackRequestHandler[T, U]) Handle(ctx context.Context, req T) (U, error) {
+	res, err := h.primary(ctx, req)
+	if err == nil || !h.triggers(ctx, err) {
+		return res, err
+	}
+	res, ferr := h.fb.Handler.Handle(ctx, req)
+	fe := &FallbackError{Primary: err, Fallback: ferr}
+	if ferr != nil {
+		return res, fe
+	}
+	if h.fb.Report != nil {
+		h.fb.Report(ctx, fe)
+	}
+	return res, nil
+}
+
+// primary invokes the embedded handler bounding its execution by the fallback's timeout, if any.
+// Events raised by the embedded handler are discarded unless it succeeds in time.
+func (h *fallbackRequestHandler[T, U]) primary(ctx context.Context, req T) (U, error) {
+	if h.fb.Timeout <= 0 {
+		ctx, commit := nestUnitOfWork(ctx)
+		res, err := h.embedded.Handle(ctx, req)
+		if err == nil {
+			commit()
+		}
+		return res, err
+	}
+	type result struct {
+		res U
+		err error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ctx, commit := nestUnitOfWork(ctx)
+	// Buffered, so the embedded handler doesn't leak if it exceeds the timeout.
+	c := make(chan result, 1)
+	go func() {
+		res, err := h.embedded.Handle(ctx, req)
+		c <- result{res: res, err: err}
+	}()
+	t := time.NewTimer(h.fb.Timeout)
+	defer t.Stop()
+	select {
+	case r := <-c:
+		if r.err == nil {
+			commit()
+		}
+		return r.res, r.err
+	case <-t.C:
+		var res U
+		return res, ErrHandlerTimeout
+	}
+}
+
+func (h *fallbackRequestHandler[T, U]) triggers(ctx context.Context, err error) bool {
+	// The caller gave up, so there's no one to return the fallback's response to.
+	if cerr := ctx.Err(); cerr != nil && errors.Is(err, cerr) {
+		return false
+	}
+	if len(h.fb.On) == 0 || errors.Is(err, ErrHandlerTimeout) {
+		return true
+	}
+	for _, target := range h.fb.On {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
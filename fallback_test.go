@@ -0,0 +1,187 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSend_Fallback(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	errInvalid := errors.New("invalid")
+	errFallback := errors.New("fallback")
+	tests := []struct {
+		name       string
+		primary    func(context.Context, DummyRequest1) (DummyResponse1, error)
+		fallback   func(context.Context, DummyRequest1) (DummyResponse1, error)
+		on         []error
+		timeout    time.Duration
+		want       string
+		wantErrs   []error
+		wantReport bool
+	}{
+		{
+			name: "primary succeeded",
+			primary: func(context.Context, DummyRequest1) (DummyResponse1, error) {
+				return DummyResponse1{String: "primary"}, nil
+			},
+			want: "primary",
+		},
+		{
+			name:       "primary failed, fallback succeeded",
+			primary:    func(context.Context, DummyRequest1) (DummyResponse1, error) { return DummyResponse1{}, errUnavailable },
+			want:       "fallback",
+			wantReport: true,
+		},
+		{
+			name:       "primary failed with listed error",
+			primary:    func(context.Context, DummyRequest1) (DummyResponse1, error) { return DummyResponse1{}, errUnavailable },
+			on:         []error{errUnavailable},
+			want:       "fallback",
+			wantReport: true,
+		},
+		{
+			name:     "primary failed with unlisted error",
+			primary:  func(context.Context, DummyRequest1) (DummyResponse1, error) { return DummyResponse1{}, errInvalid },
+			on:       []error{errUnavailable},
+			wantErrs: []error{errInvalid},
+		},
+		{
+			name: "primary timed out",
+			primary: func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+				time.Sleep(time.Second)
+				return DummyResponse1{String: "primary"}, nil
+			},
+			on:         []error{errUnavailable},
+			timeout:    10 * time.Millisecond,
+			want:       "fallback",
+			wantReport: true,
+		},
+		{
+			name:     "primary and fallback failed",
+			primary:  func(context.Context, DummyRequest1) (DummyResponse1, error) { return DummyResponse1{}, errUnavailable },
+			fallback: func(context.Context, DummyRequest1) (DummyResponse1, error) { return DummyResponse1{}, errFallback },
+			wantErrs: []error{errUnavailable, errFallback},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer clear()
+			fallback := tt.fallback
+			if fallback == nil {
+				fallback = func(context.Context, DummyRequest1) (DummyResponse1, error) {
+					return DummyResponse1{String: "fallback"}, nil
+				}
+			}
+			var reported *FallbackError
+			fb := Fallback[DummyRequest1, DummyResponse1]{
+				Handler: RequestHandlerFunc[DummyRequest1, DummyResponse1](fallback),
+				On:      tt.on,
+				Timeout: tt.timeout,
+				Report:  func(_ context.Context, err *FallbackError) { reported = err },
+			}
+			if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](RequestHandlerFunc[DummyRequest1, DummyResponse1](tt.primary), WithFallback(fb)); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			start := time.Now()
+			got, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{})
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Errorf("want send to return before timed out primary, took %v", elapsed)
+			}
+			if tt.wantErrs == nil && err != nil {
+				t.Fatalf("want success, got %v", err)
+			}
+			for _, wantErr := range tt.wantErrs {
+				if !errors.Is(err, wantErr) {
+					t.Errorf("want %v, got %v", wantErr, err)
+				}
+			}
+			if got.String != tt.want {
+				t.Errorf("want response %q, got %q", tt.want, got.String)
+			}
+			if tt.wantReport {
+				if reported == nil || !strings.Contains(reported.Error(), "fallback succeeded") {
+					t.Errorf("want fallback reported, got %v", reported)
+				}
+			} else if reported != nil {
+				t.Errorf("want no report, got %v", reported)
+			}
+		})
+	}
+}
+
+func TestSend_FallbackContextError(t *testing.T) {
+	tests := []struct {
+		name         string
+		cancel       bool
+		wantFallback bool
+	}{
+		{name: "caller's context canceled", cancel: true},
+		{name: "handler's own context canceled", wantFallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer clear()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var primary RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+				if tt.cancel {
+					cancel()
+					return DummyResponse1{}, ctx.Err()
+				}
+				return DummyResponse1{}, context.Canceled
+			}
+			var called bool
+			var fallback RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(context.Context, DummyRequest1) (DummyResponse1, error) {
+				called = true
+				return DummyResponse1{String: "fallback"}, nil
+			}
+			if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](primary, WithFallback(Fallback[DummyRequest1, DummyResponse1]{Handler: fallback})); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			_, err := Send[DummyRequest1, DummyResponse1](ctx, DummyRequest1{})
+			if called != tt.wantFallback {
+				t.Errorf("want fallback called %t, got %t", tt.wantFallback, called)
+			}
+			if !tt.wantFallback && !errors.Is(err, context.Canceled) {
+				t.Errorf("want err %v, got %v", context.Canceled, err)
+			}
+		})
+	}
+}
+
+func TestWithFallback_InvalidOption(t *testing.T) {
+	defer clear()
+	tests := []struct {
+		name     string
+		register func() error
+	}{
+		{
+			name: "mismatched fallback",
+			register: func() error {
+				return RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithFallback(Fallback[DummyRequest2, DummyResponse2]{Handler: &DummyRequestHandler2{}}))
+			},
+		},
+		{
+			name: "nil fallback handler",
+			register: func() error {
+				return RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithFallback(Fallback[DummyRequest1, DummyResponse1]{}))
+			},
+		},
+		{
+			name: "event handler",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithFallback(Fallback[DummyRequest1, DummyResponse1]{Handler: DummyRequestHandler1{}}))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.register(); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// A GatherStrategy determines how many successful responses Gather waits for.
+type GatherStrategy struct {
+	// quorum is a number of successful responses to wait for if set, otherwise Gather waits for all handlers.
+	quorum int
+	set    bool
+}
+
+// GatherAll returns a GatherStrategy that waits for all handlers, even if some of them fail.
+// Gather returns results of all handlers and fails if at least one handler fails.
+func GatherAll() GatherStrategy {
+	return GatherStrategy{}
+}
+
+// GatherFirst returns a GatherStrategy that waits for the first successful response.
+// Gather fails only if all handlers fail.
+func GatherFirst() GatherStrategy {
+	return GatherStrategy{quorum: 1, set: true}
+}
+
+// GatherQuorum returns a GatherStrategy that waits for n successful responses.
+// Gather fails as soon as the quorum cannot be reached. If n is not positive, Gather returns ErrInvalidOption.
+func GatherQuorum(n int) GatherStrategy {
+	return GatherStrategy{quorum: n, set: true}
+}
+
+// A Result is a response of a single handler collected by Gather.
+type Result[U any] struct {
+	// Name is a name of a handler, empty if the handler is not named.
+	Name string
+	// Response is a response of a handler. It's a zero value if Err is not nil.
+	Response U
+	// Err is an error returned by a handler.
+	Err error
+}
+
+// RequestGatherer is the interface that wraps the mob's Gather method.
+type RequestGatherer[T any, U any] interface {
+	// Gather sends a given request T to all gather handlers registered with a request-response pair
+	// and collects their responses according to a given strategy.
+	// Handlers are executed concurrently. Results are returned in order of completion, each with a handler's
+	// response or error. Once the strategy is satisfied, the context passed to still running handlers is canceled
+	// and their results are discarded.
+	//
+	// If the strategy is not satisfied, AggregateHandlerError containing errors of failed handlers is returned.
+	// If the strategy's quorum exceeds the number of handlers, ErrQuorumExceeded is returned.
+	// If there is no appropriate handler in the gatherer's Mob instance, ErrHandlerNotFound is returned.
+	Gather(ctx context.Context, req T, strategy GatherStrategy) ([]Result[U], error)
+}
+
+// NewRequestGatherer returns a request gatherer which uses a given Mob instance.
+func NewRequestGatherer[T any, U any](m *Mob) RequestGatherer[T, U] {
+	return &gatherer[T, U]{m: m}
+}
+
+// A gatherer is a facilitator for a given request-response type pair.
+type gatherer[T any, U any] struct {
+	m *Mob
+}
+
+func (g *gatherer[T, U]) Gather(ctx context.Context, req T, strategy GatherStrategy) ([]Result[U], error) {
+	var res U
+	k := reqHnKey{reqt: reflect.TypeOf(req), rest: reflect.TypeOf(res)}
+	if strategy.set && strategy.quorum < 1 {
+		return nil, fmt.Errorf("%w: quorum %d is not positive", ErrInvalidOption, strategy.quorum)
+	}
+	g.m.mu.RLock()
+	hns, ok := g.m.ghandlers[k]
+	g.m.mu.RUnlock()
+	if !ok {
+		return nil, ErrHandlerNotFound
+	}
+	if g.m.limiter != nil {
+		if err := g.m.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	n := len(hns)
+	quorum := strategy.quorum
+	if !strategy.set {
+		quorum = n
+	}
+	if quorum > n {
+		return nil, fmt.Errorf("%w: quorum %d, %d handlers", ErrQuorumExceeded, quorum, n)
+	}
+	g.m.stats.begin()
+	results, err := g.gather(ctx, hns, req, quorum, !strategy.set)
+	g.m.stats.end(err)
+	return results, err
+}
+
+// gather executes given handlers concurrently until a given quorum of them succeeds or it can't be reached.
+// If all is set, it waits for all handlers instead.
+func (g *gatherer[T, U]) gather(ctx context.Context, hns []*handler, req T, quorum int, all bool) ([]Result[U], error) {
+	n := len(hns)
+	ctx, cancel := context.WithCancel(withMessageID(ctx))
+	defer cancel()
+	// Buffered, so handlers finishing after Gather returns don't leak.
+	c := make(chan Result[U], n)
+	for _, hn := range hns {
+		go func(hn *handler) {
+			end := instrument(g.m, MetricGather, req, hn)
+			res, err := handle[T, U](ctx, g.m, hn, req)
+			end(err)
+			c <- Result[U]{Name: hn.name, Response: res, Err: err}
+		}(hn)
+	}
+	results := make([]Result[U], 0, n)
+	var succeeded int
+	var aggr AggregateHandlerError
+	for i := 0; i < n; i++ {
+		r := <-c
+		results = append(results, r)
+		if r.Err != nil {
+			aggr = append(aggr, r.Err)
+		} else {
+			succeeded++
+		}
+		if all {
+			continue
+		}
+		if succeeded >= quorum {
+			return results, nil
+		}
+		if n-len(aggr) < quorum {
+			break
+		}
+	}
+	if len(aggr) > 0 {
+		return results, aggr
+	}
+	return results, nil
+}
+
+// RegisterGatherHandlerTo adds a given request handler to the gather handlers of the given Mob instance.
+// Returns nil if the handler added successfully, an error otherwise.
+//
+// Multiple gather handlers can be registered for a single request-response pair. They're invoked
+// through Gather only, Send dispatches to handlers registered with RegisterRequestHandlerTo.
+func RegisterGatherHandlerTo[T any, U any](m *Mob, rhn RequestHandler[T, U], opts ...Option) error {
+	if !isValid(rhn) {
+		return ErrInvalidHandler
+	}
+	var req T
+	var res U
+	k := reqHnKey{reqt: reflect.TypeOf(req), rest: reflect.TypeOf(res)}
+	hn, err := newRequestHandler(m, rhn, opts)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.ghandlers[k] = append(m.ghandlers[k], hn)
+	registerType(m, k.reqt)
+	registerType(m, k.rest)
+	m.mu.Unlock()
+	return nil
+}
+
+// RegisterGatherHandler adds a given request handler to the gather handlers of the global Mob instance.
+// Returns nil if the handler added successfully, an error otherwise.
+//
+// Multiple gather handlers can be registered for a single request-response pair. They're invoked
+// through Gather only, Send dispatches to handlers registered with RegisterRequestHandler.
+func RegisterGatherHandler[T any, U any](hn RequestHandler[T, U], opts ...Option) error {
+	return RegisterGatherHandlerTo(m, hn, opts...)
+}
+
+// Gather sends a given request T to all gather handlers registered with a request-response pair
+// and collects their responses according to a given strategy.
+//
+// If there is no appropriate handler in the global Mob instance, ErrHandlerNotFound is returned.
+func Gather[T any, U any](ctx context.Context, req T, strategy GatherStrategy) ([]Result[U], error) {
+	return NewRequestGatherer[T, U](m).Gather(ctx, req, strategy)
+}
@@ -0,0 +1,166 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func gatherHandler(s string, err error, delay time.Duration) RequestHandlerFunc[DummyRequest1, DummyResponse1] {
+	return func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return DummyResponse1{}, ctx.Err()
+		}
+		return DummyResponse1{String: s}, err
+	}
+}
+
+func TestGather_HandlerNotFound(t *testing.T) {
+	defer clear()
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := Gather[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}, GatherAll()); err != ErrHandlerNotFound {
+		t.Errorf("want error %v, got %v", ErrHandlerNotFound, err)
+	}
+}
+
+func TestGather(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	type handler struct {
+		s     string
+		err   error
+		delay time.Duration
+	}
+	tests := []struct {
+		name     string
+		handlers []handler
+		strategy GatherStrategy
+		want     []string
+		wantErrs []error
+	}{
+		{
+			name:     "all",
+			handlers: []handler{{s: "a"}, {s: "b"}, {s: "c"}},
+			strategy: GatherAll(),
+			want:     []string{"a", "b", "c"},
+		},
+		{
+			name:     "all, one failed",
+			handlers: []handler{{err: errFirst}, {s: "b"}, {s: "c", delay: 20 * time.Millisecond}},
+			strategy: GatherAll(),
+			want:     []string{"b", "c"},
+			wantErrs: []error{errFirst},
+		},
+		{
+			name:     "first success",
+			handlers: []handler{{err: errFirst}, {s: "b", delay: 10 * time.Millisecond}, {s: "c", delay: time.Second}},
+			strategy: GatherFirst(),
+			want:     []string{"b"},
+		},
+		{
+			name:     "first success, all failed",
+			handlers: []handler{{err: errFirst}, {err: errSecond}},
+			strategy: GatherFirst(),
+			wantErrs: []error{errFirst, errSecond},
+		},
+		{
+			name:     "quorum",
+			handlers: []handler{{s: "a"}, {s: "b", delay: 10 * time.Millisecond}, {s: "c", delay: time.Second}},
+			strategy: GatherQuorum(2),
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "quorum not reached",
+			handlers: []handler{{err: errFirst}, {err: errSecond, delay: 10 * time.Millisecond}, {s: "c", delay: time.Second}},
+			strategy: GatherQuorum(2),
+			wantErrs: []error{errFirst, errSecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer clear()
+			for _, h := range tt.handlers {
+				if err := RegisterGatherHandler[DummyRequest1, DummyResponse1](gatherHandler(h.s, h.err, h.delay)); err != nil {
+					t.Fatalf("register handler: %v", err)
+				}
+			}
+			start := time.Now()
+			results, err := Gather[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}, tt.strategy)
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Errorf("want gather to return before slow handlers, took %v", elapsed)
+			}
+			if tt.wantErrs == nil && err != nil {
+				t.Fatalf("want success, got %v", err)
+			}
+			for _, wantErr := range tt.wantErrs {
+				if !errors.Is(err, wantErr) {
+					t.Errorf("want %v, got %v", wantErr, err)
+				}
+			}
+			if tt.want == nil {
+				return
+			}
+			var got []string
+			for _, r := range results {
+				if r.Err == nil {
+					got = append(got, r.Response.String)
+				}
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("want responses %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("want responses %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGather_Named(t *testing.T) {
+	defer clear()
+	errDummy := errors.New("dummy")
+	if err := RegisterGatherHandler[DummyRequest1, DummyResponse1](gatherHandler("", errDummy, 0), WithName("DummyGatherHandler")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	results, err := Gather[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}, GatherAll())
+	if !errors.Is(err, errDummy) {
+		t.Fatalf("want %v, got %v", errDummy, err)
+	}
+	if len(results) != 1 || results[0].Name != "DummyGatherHandler" {
+		t.Fatalf("want named result, got %v", results)
+	}
+	if got := results[0].Err.Error(); got != "DummyGatherHandler: dummy" {
+		t.Errorf("want named err, got %s", got)
+	}
+}
+
+func TestGather_QuorumExceedsHandlers(t *testing.T) {
+	defer clear()
+	if err := RegisterGatherHandler[DummyRequest1, DummyResponse1](gatherHandler("a", nil, 0)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := Gather[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}, GatherQuorum(2)); !errors.Is(err, ErrQuorumExceeded) {
+		t.Errorf("want err %v, got %v", ErrQuorumExceeded, err)
+	}
+}
+
+func TestGather_InvalidQuorum(t *testing.T) {
+	defer clear()
+	if err := RegisterGatherHandler[DummyRequest1, DummyResponse1](gatherHandler("a", nil, 0)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	for _, n := range []int{0, -1} {
+		if _, err := Gather[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}, GatherQuorum(n)); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("want err %v for quorum %d, got %v", ErrInvalidOption, n, err)
+		}
+	}
+}
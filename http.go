@@ -0,0 +1,209 @@
+package mob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Paths' prefixes of requests and events sent over HTTP. A prefix is followed by a name of a message's type.
+const (
+	httpSendPrefix   = "/send/"
+	httpNotifyPrefix = "/notify/"
+)
+
+// httpMetadataHeader is a header carrying Metadata encoded as a URL query string.
+const httpMetadataHeader = "Mob-Metadata"
+
+// Codes of errors sent over HTTP.
+const (
+	httpCodeNotFound        = "not_found"
+	httpCodeTooLarge        = "too_large"
+	httpCodeUnknownType     = "unknown_type"
+	httpCodeBadRequest      = "bad_request"
+	httpCodeHandlerNotFound = "handler_not_found"
+	httpCodeAmbiguous       = "ambiguous_request"
+	httpCodeInvalidHandler  = "invalid_handler"
+	httpCodeRateLimited     = "rate_limited"
+	httpCodeTimeout         = "timeout"
+	httpCodeClosed          = "closed"
+	httpCodeHandler         = "handler_error"
+)
+
+// DefaultHTTPMaxBodySize is a default maximum size of a message's body accepted over HTTP, 1 MiB.
+const DefaultHTTPMaxBodySize = 1 << 20
+
+// httpInternalError is a message of 500 Internal Server Error responses, unless errors are exposed.
+const httpInternalError = "mob: internal error"
+
+// HTTPOptions configures an HTTP handler exposing a Mob instance.
+type HTTPOptions struct {
+	// MaxBodySize is a maximum size of a message's body in bytes. Zero means DefaultHTTPMaxBodySize.
+	MaxBodySize int64
+	// ExposeErrors sends messages of errors resulting in 500 Internal Server Error to clients.
+	// By default, such responses carry a generic message and errors are passed to the Mob's error handler.
+	ExposeErrors bool
+	// AcceptMetadata reports whether a metadata key sent by a client is accepted. Metadata can carry
+	// e.g. tenant IDs or auth principals, so if nil, metadata sent by clients is ignored.
+	AcceptMetadata func(key string) bool
+}
+
+// AcceptMetadataKeys returns a function accepting only given metadata keys, to be used as HTTPOptions.AcceptMetadata.
+func AcceptMetadataKeys(keys ...string) func(key string) bool {
+	accepted := make(map[string]token, len(keys))
+	for _, k := range keys {
+		accepted[k] = token{}
+	}
+	return func(key string) bool {
+		_, ok := accepted[key]
+		return ok
+	}
+}
+
+// An httpError is an error sent over HTTP.
+type httpError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewHTTPHandler returns an http.Handler exposing the given Mob instance over HTTP.
+//
+// POST /send/{request-type} decodes a JSON body into a request of a type registered under a given name
+// in the Mob instance's type registry, sends it and encodes the response as JSON.
+// POST /notify/{event-type} decodes a JSON body into an event the same way and notifies it.
+// No content is returned on success. Metadata keys accepted by the options' AcceptMetadata are read
+// from the Mob-Metadata header.
+//
+// Errors are returned as a JSON object with a code and a message. A message of an unknown type
+// or without a handler results in 404 Not Found, a malformed message in 400 Bad Request, a body larger
+// than the options' MaxBodySize in 413 Request Entity Too Large, ErrRateLimited in 429 Too Many Requests,
+// ErrHandlerTimeout and exceeded deadlines in 504 Gateway Timeout, ErrClosed in 503 Service Unavailable
+// and other errors in 500 Internal Server Error.
+//
+// To serve the handler under a path other than the root, use http.StripPrefix.
+func NewHTTPHandler(m *Mob, o HTTPOptions) http.Handler {
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = DefaultHTTPMaxBodySize
+	}
+	return &httpHandler{m: m, o: o}
+}
+
+type httpHandler struct {
+	m *Mob
+	o HTTPOptions
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var name string
+	var send bool
+	switch {
+	case strings.HasPrefix(r.URL.Path, httpSendPrefix):
+		name, send = strings.TrimPrefix(r.URL.Path, httpSendPrefix), true
+	case strings.HasPrefix(r.URL.Path, httpNotifyPrefix):
+		name = strings.TrimPrefix(r.URL.Path, httpNotifyPrefix)
+	default:
+		writeHTTPError(w, http.StatusNotFound, httpCodeNotFound, "mob: unknown path "+r.URL.Path)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeHTTPError(w, http.StatusMethodNotAllowed, httpCodeBadRequest, "mob: method not allowed")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.o.MaxBodySize))
+	if err != nil {
+		// MaxBytesReader fails once the limit is read and there's more to read.
+		if int64(len(body)) >= h.o.MaxBodySize {
+			writeHTTPError(w, http.StatusRequestEntityTooLarge, httpCodeTooLarge, "mob: body too large")
+			return
+		}
+		writeHTTPError(w, http.StatusBadRequest, httpCodeBadRequest, err.Error())
+		return
+	}
+	msg, err := DecodeTo(h.m, JSONCodec{}, name, body)
+	if err != nil {
+		if errors.Is(err, ErrUnknownType) {
+			writeHTTPError(w, http.StatusNotFound, httpCodeUnknownType, err.Error())
+			return
+		}
+		writeHTTPError(w, http.StatusBadRequest, httpCodeBadRequest, err.Error())
+		return
+	}
+	ctx := r.Context()
+	if hmd := r.Header.Get(httpMetadataHeader); hmd != "" && h.o.AcceptMetadata != nil {
+		md, err := decodeMetadata(hmd)
+		if err != nil {
+			writeHTTPError(w, http.StatusBadRequest, httpCodeBadRequest, "mob: malformed metadata: "+err.Error())
+			return
+		}
+		for k := range md {
+			if !h.o.AcceptMetadata(k) {
+				delete(md, k)
+			}
+		}
+		ctx = withMetadata(ctx, md)
+	}
+	if !send {
+		if err := notifyAny(ctx, h.m, msg); err != nil {
+			h.writeDispatchError(ctx, w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	res, err := sendAny(ctx, h.m, msg)
+	if err != nil {
+		h.writeDispatchError(ctx, w, err)
+		return
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		h.writeInternalError(ctx, w, httpCodeHandler, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// writeDispatchError writes an error returned by Send or Notify.
+func (h *httpHandler) writeDispatchError(ctx context.Context, w http.ResponseWriter, err error) {
+	// Compared directly, so handlers' errors wrapping the errors aren't reported as the handler's own.
+	switch {
+	case err == ErrHandlerNotFound:
+		writeHTTPError(w, http.StatusNotFound, httpCodeHandlerNotFound, err.Error())
+	case err == ErrAmbiguousRequest:
+		writeHTTPError(w, http.StatusBadRequest, httpCodeAmbiguous, err.Error())
+	case errors.Is(err, ErrUnmarshal):
+		writeHTTPError(w, http.StatusBadRequest, httpCodeBadRequest, err.Error())
+	case errors.Is(err, ErrInvalidHandler):
+		h.writeInternalError(ctx, w, httpCodeInvalidHandler, err)
+	case errors.Is(err, ErrRateLimited):
+		writeHTTPError(w, http.StatusTooManyRequests, httpCodeRateLimited, err.Error())
+	case errors.Is(err, ErrHandlerTimeout), errors.Is(err, context.DeadlineExceeded):
+		writeHTTPError(w, http.StatusGatewayTimeout, httpCodeTimeout, err.Error())
+	case errors.Is(err, ErrClosed):
+		writeHTTPError(w, http.StatusServiceUnavailable, httpCodeClosed, err.Error())
+	default:
+		h.writeInternalError(ctx, w, httpCodeHandler, err)
+	}
+}
+
+// writeInternalError writes an error as 500 Internal Server Error. Unless errors are exposed,
+// the error's message is replaced with a generic one and the error is passed to the Mob's error handler.
+func (h *httpHandler) writeInternalError(ctx context.Context, w http.ResponseWriter, code string, err error) {
+	if h.o.ExposeErrors {
+		writeHTTPError(w, http.StatusInternalServerError, code, err.Error())
+		return
+	}
+	handleError(ctx, h.m, err)
+	writeHTTPError(w, http.StatusInternalServerError, code, httpInternalError)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(httpError{Code: code, Message: msg})
+}
@@ -0,0 +1,287 @@
+package mob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHTTPMob(t *testing.T) *Mob {
+	m := New()
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, req DummyRequest1) (DummyResponse1, error) {
+		switch req.String {
+		case "fail":
+			return DummyResponse1{}, errors.New("dummy")
+		case "limit":
+			return DummyResponse1{}, ErrRateLimited
+		case "timeout":
+			return DummyResponse1{}, ErrHandlerTimeout
+		}
+		return DummyResponse1{String: req.String, Int: 997}, nil
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest2, DummyResponse1](m, RequestHandlerFunc[DummyRequest2, DummyResponse1](func(context.Context, DummyRequest2) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	})); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest2, DummyResponse2](m, &DummyRequestHandler2{}); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	var ehf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+		if ev.String == "fail" {
+			return errors.New("dummy")
+		}
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	return m
+}
+
+func TestHTTPHandler(t *testing.T) {
+	srv := httptest.NewServer(NewHTTPHandler(newTestHTTPMob(t), HTTPOptions{}))
+	defer srv.Close()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantBody   string
+	}{
+		{
+			name:       "send",
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			body:       `{"String":"string"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"String":"string","Int":997,"Bool":false,"Time":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:       "notify",
+			path:       "/notify/github.com/erni27/mob.DummyEvent1",
+			body:       `{"String":"string"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "unknown type",
+			path:       "/notify/github.com/erni27/mob.Unknown",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   httpCodeUnknownType,
+		},
+		{
+			name:       "handler not found",
+			path:       "/notify/github.com/erni27/mob.DummyRequest1",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   httpCodeHandlerNotFound,
+		},
+		{
+			name:       "unknown path",
+			path:       "/unknown",
+			wantStatus: http.StatusNotFound,
+			wantCode:   httpCodeNotFound,
+		},
+		{
+			name:       "malformed body",
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			body:       `{"String":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   httpCodeBadRequest,
+		},
+		{
+			name:       "ambiguous request",
+			path:       "/send/github.com/erni27/mob.DummyRequest2",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   httpCodeAmbiguous,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodGet,
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   httpCodeBadRequest,
+		},
+		{
+			name:       "request handler error",
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			body:       `{"String":"fail"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   httpCodeHandler,
+		},
+		{
+			name:       "event handler error",
+			path:       "/notify/github.com/erni27/mob.DummyEvent1",
+			body:       `{"String":"fail"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   httpCodeHandler,
+		},
+		{
+			name:       "rate limited",
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			body:       `{"String":"limit"}`,
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   httpCodeRateLimited,
+		},
+		{
+			name:       "timeout",
+			path:       "/send/github.com/erni27/mob.DummyRequest1",
+			body:       `{"String":"timeout"}`,
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   httpCodeTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			res, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("do request: %v", err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, res.StatusCode)
+			}
+			if tt.wantCode != "" {
+				var herr httpError
+				if err := json.NewDecoder(res.Body).Decode(&herr); err != nil {
+					t.Fatalf("decode error: %v", err)
+				}
+				if herr.Code != tt.wantCode {
+					t.Errorf("want code %s, got %s", tt.wantCode, herr.Code)
+				}
+				return
+			}
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("want body %s, got %s", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_Deadline(t *testing.T) {
+	m := New()
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		<-ctx.Done()
+		return DummyResponse1{}, ctx.Err()
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, hf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/send/github.com/erni27/mob.DummyRequest1", strings.NewReader(`{}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	NewHTTPHandler(m, HTTPOptions{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("want status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+}
+
+func TestHTTPHandler_MaxBodySize(t *testing.T) {
+	h := NewHTTPHandler(newTestHTTPMob(t), HTTPOptions{MaxBodySize: 16})
+	for _, tt := range []struct {
+		body       string
+		wantStatus int
+	}{
+		{body: `{"String":"a"}`, wantStatus: http.StatusOK},
+		{body: `{"String":"abcdefghij"}`, wantStatus: http.StatusRequestEntityTooLarge},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/send/github.com/erni27/mob.DummyRequest1", strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("want status %d for body %s, got %d", tt.wantStatus, tt.body, rec.Code)
+		}
+	}
+}
+
+func TestHTTPHandler_InternalError(t *testing.T) {
+	tests := []struct {
+		name        string
+		o           HTTPOptions
+		wantMessage string
+		wantHandled bool
+	}{
+		{name: "generic", wantMessage: httpInternalError, wantHandled: true},
+		{name: "exposed", o: HTTPOptions{ExposeErrors: true}, wantMessage: "dummy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestHTTPMob(t)
+			var handled error
+			SetErrorHandlerTo(m, func(_ context.Context, err error) { handled = err })
+			req := httptest.NewRequest(http.MethodPost, "/send/github.com/erni27/mob.DummyRequest1", strings.NewReader(`{"String":"fail"}`))
+			rec := httptest.NewRecorder()
+			NewHTTPHandler(m, tt.o).ServeHTTP(rec, req)
+			var herr httpError
+			if err := json.NewDecoder(rec.Body).Decode(&herr); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if rec.Code != http.StatusInternalServerError || herr.Message != tt.wantMessage {
+				t.Errorf("want status %d and message %q, got %d and %q", http.StatusInternalServerError, tt.wantMessage, rec.Code, herr.Message)
+			}
+			if (handled != nil) != tt.wantHandled {
+				t.Errorf("want error handled %t, got %v", tt.wantHandled, handled)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_AcceptMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		o    HTTPOptions
+		want Metadata
+	}{
+		{name: "ignored by default"},
+		{name: "accepted keys", o: HTTPOptions{AcceptMetadata: AcceptMetadataKeys("tenant")}, want: Metadata{"tenant": "acme"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			var got Metadata
+			var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+				got = withoutIDs(MetadataFrom(ctx))
+				return nil
+			}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/notify/github.com/erni27/mob.DummyEvent1", strings.NewReader(`{}`))
+			req.Header.Set(httpMetadataHeader, "tenant=acme&principal=admin")
+			rec := httptest.NewRecorder()
+			NewHTTPHandler(m, tt.o).ServeHTTP(rec, req)
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("want status %d, got %d", http.StatusNoContent, rec.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want metadata %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
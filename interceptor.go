@@ -23,6 +23,25 @@ func AddInterceptor(interceptor Interceptor) {
 	AddInterceptorTo(m, interceptor)
 }
 
+// NotifyInvoker is a function called by an EventInterceptor to invoke
+// the next EventInterceptor in the chain or the underlying handlers.
+type NotifyInvoker func(ctx context.Context, event interface{}) error
+
+// EventInterceptor intercepts an invocation of a Notify method.
+type EventInterceptor func(ctx context.Context, event interface{}, invoker NotifyInvoker) error
+
+// AddEventInterceptorTo adds an EventInterceptor to the given Mob instance.
+// EventInterceptors are invoked in order they're added to the chain.
+func AddEventInterceptorTo(m *Mob, interceptor EventInterceptor) {
+	m.einterceptors = append(m.einterceptors, interceptor)
+}
+
+// AddEventInterceptor adds an EventInterceptor to the global Mob instance.
+// EventInterceptors are invoked in order they're added to the chain.
+func AddEventInterceptor(interceptor EventInterceptor) {
+	AddEventInterceptorTo(m, interceptor)
+}
+
 func chainInterceptors(interceptors []Interceptor) Interceptor {
 	if len(interceptors) == 0 {
 		return nil
@@ -43,3 +62,24 @@ func buildInvoker(inner SendInvoker, interceptors []Interceptor, depth int) func
 		return interceptors[depth+1](ctx, req, buildInvoker(inner, interceptors, depth+1))
 	}
 }
+
+func chainEventInterceptors(interceptors []EventInterceptor) EventInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	if len(interceptors) == 1 {
+		return interceptors[0]
+	}
+	return func(ctx context.Context, event interface{}, invoker NotifyInvoker) error {
+		return interceptors[0](ctx, event, buildNotifyInvoker(invoker, interceptors, 0))
+	}
+}
+
+func buildNotifyInvoker(inner NotifyInvoker, interceptors []EventInterceptor, depth int) NotifyInvoker {
+	if len(interceptors)-1 == depth {
+		return inner
+	}
+	return func(ctx context.Context, event interface{}) error {
+		return interceptors[depth+1](ctx, event, buildNotifyInvoker(inner, interceptors, depth+1))
+	}
+}
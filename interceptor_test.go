@@ -223,3 +223,45 @@ func TestUseInterceptor_MalformedResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestAddEventInterceptor_Order(t *testing.T) {
+	defer clear()
+	var order []int
+	for i := 0; i < 3; i++ {
+		i := i
+		AddEventInterceptor(func(ctx context.Context, event interface{}, invoker NotifyInvoker) error {
+			order = append(order, i)
+			return invoker(ctx, event)
+		})
+	}
+	var got DummyEvent1
+	hn := &DummyEventHandler1{handleFunc: func(_ context.Context, ev DummyEvent1) error {
+		got = ev
+		return nil
+	}}
+	if err := RegisterEventHandler[DummyEvent1](hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{Int: 1}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("want interceptors invoked in order, got %v", order)
+	}
+	if got.Int != 1 {
+		t.Errorf("want event passed to handler, got %v", got)
+	}
+}
+
+func TestAddEventInterceptor_ModifyEvent(t *testing.T) {
+	defer clear()
+	AddEventInterceptor(func(ctx context.Context, event interface{}, invoker NotifyInvoker) error {
+		return invoker(ctx, "malformed")
+	})
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); !errors.Is(err, ErrUnmarshal) {
+		t.Errorf("want err %v, got %v", ErrUnmarshal, err)
+	}
+}
@@ -44,15 +44,49 @@ type sender[T any, U any] struct {
 
 func (s *sender[T, U]) Send(ctx context.Context, req T) (U, error) {
 	var res U
-	var err error
 	k := reqHnKey{reqt: reflect.TypeOf(req), rest: reflect.TypeOf(res)}
+	s.m.mu.RLock()
 	hn, ok := s.m.rhandlers[k]
+	s.m.mu.RUnlock()
 	if !ok {
 		return res, ErrHandlerNotFound
 	}
+	if s.m.limiter != nil {
+		if err := s.m.limiter.wait(ctx); err != nil {
+			return res, err
+		}
+	}
+	s.m.stats.begin()
+	ctx, endSpan := trace(withMessageID(ctx), s.m, SpanSend, req, hn)
+	end := instrument(s.m, MetricSend, req, hn)
+	res, err := handle[T, U](ctx, s.m, hn, req)
+	end(err)
+	endSpan(err)
+	s.m.stats.end(err)
+	return res, err
+}
+
+// A handlerNameKey is a context key of a name of a request handler.
+type handlerNameKey struct{}
+
+// HandlerName returns a name of a request handler a request sent with a given context is handled by,
+// empty if the handler is unnamed. It's available to Interceptors.
+func HandlerName(ctx context.Context) string {
+	name, _ := ctx.Value(handlerNameKey{}).(string)
+	return name
+}
+
+// handle invokes a given request handler through the Mob's interceptors chain.
+func handle[T any, U any](ctx context.Context, m *Mob, hn *handler, req T) (U, error) {
+	var res U
+	var err error
+	var ok bool
 	// Dispatching result not checked because if a handler is found then it should always satisfy RequestHandler[T, U] interface.
 	dhn, _ := hn.embedded.(RequestHandler[T, U])
-	if len(s.m.interceptors) != 0 {
+	if len(m.interceptors) != 0 {
+		if HandlerName(ctx) != hn.name {
+			ctx = context.WithValue(ctx, handlerNameKey{}, hn.name)
+		}
 		invoker := func(ctx context.Context, creq interface{}) (interface{}, error) {
 			req, ok := creq.(T)
 			if !ok {
@@ -60,7 +94,7 @@ func (s *sender[T, U]) Send(ctx context.Context, req T) (U, error) {
 			}
 			return dhn.Handle(ctx, req)
 		}
-		chained := chainInterceptors(s.m.interceptors)
+		chained := chainInterceptors(m.interceptors)
 		cres, cerr := chained(ctx, req, invoker)
 		if cerr == nil {
 			res, ok = cres.(U)
@@ -95,15 +129,91 @@ func RegisterRequestHandlerTo[T any, U any](m *Mob, rhn RequestHandler[T, U], op
 	var req T
 	var res U
 	k := reqHnKey{reqt: reflect.TypeOf(req), rest: reflect.TypeOf(res)}
+	hn, err := newRequestHandler(m, rhn, opts)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if _, ok := m.rhandlers[k]; ok {
 		return ErrDuplicateHandler
 	}
-	hn := &handler{embedded: rhn}
+	m.rhandlers[k] = hn
+	s := NewRequestSender[T, U](m)
+	m.rsenders[k.reqt] = append(m.rsenders[k.reqt], func(ctx context.Context, creq interface{}) (interface{}, error) {
+		req, ok := creq.(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: request is %T, want %T", ErrUnmarshal, creq, req)
+		}
+		return s.Send(ctx, req)
+	})
+	registerType(m, k.reqt)
+	registerType(m, k.rest)
+	return nil
+}
+
+// newRequestHandler applies given options to a request handler.
+func newRequestHandler[T any, U any](m *Mob, rhn RequestHandler[T, U], opts []Option) (*handler, error) {
+	hn := &handler{}
 	for _, opt := range opts {
 		opt.apply(hn)
 	}
-	m.rhandlers[k] = hn
-	return nil
+	if hn.filter != nil {
+		return nil, fmt.Errorf("%w: filter applies only to event handlers", ErrInvalidOption)
+	}
+	if hn.once {
+		return nil, fmt.Errorf("%w: once applies only to event handlers", ErrInvalidOption)
+	}
+	if hn.coalescing != nil {
+		return nil, fmt.Errorf("%w: debounce and throttle apply only to event handlers", ErrInvalidOption)
+	}
+	if hn.limit != nil {
+		if err := hn.limit.validate(); err != nil {
+			return nil, err
+		}
+		rhn = &rateLimitedRequestHandler[T, U]{embedded: rhn, l: newLimiter(*hn.limit)}
+	}
+	if hn.retry != nil {
+		if err := hn.retry.validate(); err != nil {
+			return nil, err
+		}
+		rhn = &retryingRequestHandler[T, U]{embedded: rhn, r: *hn.retry, m: m}
+	}
+	if hn.fallback != nil {
+		fb, ok := hn.fallback.(*Fallback[T, U])
+		if !ok {
+			return nil, fmt.Errorf("%w: fallback is %T, want %T", ErrInvalidOption, hn.fallback, fb)
+		}
+		if !isValid(fb.Handler) {
+			return nil, fmt.Errorf("%w: invalid fallback handler", ErrInvalidOption)
+		}
+		rhn = &fallbackRequestHandler[T, U]{embedded: rhn, fb: *fb}
+	}
+	if hn.singleflight {
+		if !reflect.TypeOf((*T)(nil)).Elem().Comparable() {
+			return nil, fmt.Errorf("%w: singleflight requires comparable request type", ErrInvalidOption)
+		}
+		rhn = newSingleflightRequestHandler(rhn, hn.singleflightKeys)
+	}
+	hn.embedded = rhn
+	return hn, nil
+}
+
+// sendAny sends a request of a type unknown at compile time to the given Mob instance.
+// If no handler is registered for the request's type, ErrHandlerNotFound is returned. If handlers with different
+// response types are registered for it, ErrAmbiguousRequest is returned.
+func sendAny(ctx context.Context, m *Mob, req interface{}) (interface{}, error) {
+	m.mu.RLock()
+	ss := m.rsenders[reflect.TypeOf(req)]
+	m.mu.RUnlock()
+	switch len(ss) {
+	case 0:
+		return nil, ErrHandlerNotFound
+	case 1:
+		return ss[0](ctx, req)
+	default:
+		return nil, ErrAmbiguousRequest
+	}
 }
 
 // RegisterRequestHandler adds a given request handler to the global Mob instance.
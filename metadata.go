@@ -0,0 +1,108 @@
+package mob
+
+import (
+	"context"
+	"net/url"
+)
+
+// Metadata is data carried alongside requests and events, such as correlation IDs, tenant IDs or auth principals.
+// Keys are case-sensitive.
+type Metadata map[string]string
+
+// A metadataKey is a context key of Metadata.
+type metadataKey struct{}
+
+// WithMetadata returns a copy of a given context carrying metadata with a given key set to a given value.
+//
+// Metadata is shared by interceptors and handlers and propagated to requests and events dispatched
+// with the returned context or a context derived from it. It's transported by remote request senders
+// and event notifiers, event buses, outboxes, event stores and dead letter queues.
+func WithMetadata(ctx context.Context, key, value string) context.Context {
+	return withMetadata(ctx, Metadata{key: value})
+}
+
+// MetadataFrom returns a copy of metadata carried by a given context, nil if there is none.
+// IDs of a message handled with the context are included.
+func MetadataFrom(ctx context.Context) Metadata {
+	v := metadataFrom(ctx)
+	if v == nil {
+		return nil
+	}
+	return v.metadata()
+}
+
+// A metadataValue is a value of a context's metadataKey.
+type metadataValue struct {
+	// md is never modified, it's copied instead.
+	md Metadata
+	// ids are IDs of a message handled with the context, nil outside of dispatch.
+	ids *messageIDs
+}
+
+func metadataFrom(ctx context.Context) *metadataValue {
+	v, _ := ctx.Value(metadataKey{}).(*metadataValue)
+	return v
+}
+
+// metadata returns a copy of the value's metadata with message IDs set, nil if it's empty.
+func (v *metadataValue) metadata() Metadata {
+	n := len(v.md)
+	if v.ids != nil {
+		n += 3
+	}
+	if n == 0 {
+		return nil
+	}
+	cp := make(Metadata, n)
+	for k, val := range v.md {
+		cp[k] = val
+	}
+	if v.ids != nil {
+		cp[MessageIDKey] = v.ids.message()
+		cp[CorrelationIDKey] = v.ids.correlationID()
+		if id := v.ids.causationID(); id != "" {
+			cp[CausationIDKey] = id
+		} else {
+			delete(cp, CausationIDKey)
+		}
+	}
+	return cp
+}
+
+// withMetadata returns a copy of a given context carrying given metadata merged into the context's one.
+// IDs of a message handled with the context are merged as well, so given IDs override them.
+func withMetadata(ctx context.Context, md Metadata) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	merged := MetadataFrom(ctx)
+	if merged == nil {
+		merged = make(Metadata, len(md))
+	}
+	for k, v := range md {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, metadataKey{}, &metadataValue{md: merged})
+}
+
+// encode encodes metadata as a URL query string.
+func (md Metadata) encode() string {
+	vs := make(url.Values, len(md))
+	for k, v := range md {
+		vs.Set(k, v)
+	}
+	return vs.Encode()
+}
+
+// decodeMetadata decodes metadata encoded as a URL query string.
+func decodeMetadata(s string) (Metadata, error) {
+	vs, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	md := make(Metadata, len(vs))
+	for k := range vs {
+		md[k] = vs.Get(k)
+	}
+	return md, nil
+}
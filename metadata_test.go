@@ -0,0 +1,177 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	ctx := context.Background()
+	if md := MetadataFrom(ctx); md != nil {
+		t.Errorf("want no metadata, got %v", md)
+	}
+	parent := WithMetadata(ctx, "tenant", "acme")
+	child := WithMetadata(parent, "user", "erni27")
+	if want, got := (Metadata{"tenant": "acme"}), MetadataFrom(parent); !reflect.DeepEqual(got, want) {
+		t.Errorf("want parent's metadata %v, got %v", want, got)
+	}
+	if want, got := (Metadata{"tenant": "acme", "user": "erni27"}), MetadataFrom(child); !reflect.DeepEqual(got, want) {
+		t.Errorf("want child's metadata %v, got %v", want, got)
+	}
+	md := MetadataFrom(child)
+	md["tenant"] = "modified"
+	if got := MetadataFrom(child)["tenant"]; got != "acme" {
+		t.Errorf("want metadata copied, got %s", got)
+	}
+	decoded, err := decodeMetadata(Metadata{"a b": "c&d=e"}.encode())
+	if err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if want := (Metadata{"a b": "c&d=e"}); !reflect.DeepEqual(decoded, want) {
+		t.Errorf("want %v, got %v", want, decoded)
+	}
+}
+
+func TestMetadata_NestedDispatch(t *testing.T) {
+	defer clear()
+	got := make(chan Metadata, 1)
+	var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+		got <- withoutIDs(MetadataFrom(ctx))
+		return nil
+	}
+	if err := RegisterEventHandler[DummyEvent1](ehf); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, Notify(WithMetadata(ctx, "user", "erni27"), DummyEvent1{})
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](rhf); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	AddInterceptor(func(ctx context.Context, req interface{}, invoker SendInvoker) (interface{}, error) {
+		return invoker(WithMetadata(ctx, "tenant", "acme"), req)
+	})
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if want, md := (Metadata{"tenant": "acme", "user": "erni27"}), <-got; !reflect.DeepEqual(md, want) {
+		t.Errorf("want %v, got %v", want, md)
+	}
+}
+
+func TestMetadata_Transports(t *testing.T) {
+	want := Metadata{"tenant": "acme"}
+	ctx := WithMetadata(context.Background(), "tenant", "acme")
+	newMob := func(t *testing.T) (*Mob, chan Metadata) {
+		m := New()
+		got := make(chan Metadata, 1)
+		var ehf EventHandlerFunc[DummyEvent1] = func(ctx context.Context, _ DummyEvent1) error {
+			got <- withoutIDs(MetadataFrom(ctx))
+			return nil
+		}
+		if err := RegisterEventHandlerTo[DummyEvent1](m, ehf); err != nil {
+			t.Fatalf("register handler: %v", err)
+		}
+		return m, got
+	}
+	tests := []struct {
+		name     string
+		transmit func(t *testing.T, m *Mob)
+	}{
+		{
+			name: "http",
+			transmit: func(t *testing.T, m *Mob) {
+				srv := httptest.NewServer(NewHTTPHandler(m, HTTPOptions{AcceptMetadata: AcceptMetadataKeys("tenant")}))
+				defer srv.Close()
+				if err := NewRemoteEventNotifier[DummyEvent1](m, Remote{URL: srv.URL, Client: srv.Client()}).Notify(ctx, DummyEvent1{}); err != nil {
+					t.Fatalf("notify: %v", err)
+				}
+			},
+		},
+		{
+			name: "bus",
+			transmit: func(t *testing.T, m *Mob) {
+				bus := NewLoopbackBus()
+				cctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() { _ = ConsumeTo(cctx, m, bus, "g") }()
+				for {
+					bus.mu.Lock()
+					n := len(bus.groups)
+					bus.mu.Unlock()
+					if n == 1 {
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				if err := NewBusEventNotifier[DummyEvent1](m, bus).Notify(ctx, DummyEvent1{}); err != nil {
+					t.Fatalf("notify: %v", err)
+				}
+			},
+		},
+		{
+			name: "outbox",
+			transmit: func(t *testing.T, m *Mob) {
+				db, _ := openOutboxDB(t)
+				s := NewSQLOutboxStore(m, db, "outbox", PlaceholderQuestion)
+				if err := StageTo(ctx, m, s, DummyEvent1{}); err != nil {
+					t.Fatalf("stage: %v", err)
+				}
+				if _, err := RelayOutboxTo(context.Background(), m, s, 1); err != nil {
+					t.Fatalf("relay: %v", err)
+				}
+			},
+		},
+		{
+			name: "dead letter",
+			transmit: func(t *testing.T, m *Mob) {
+				failing := New()
+				q := NewMemoryDeadLetterQueue()
+				SetDeadLetterQueueTo(failing, q)
+				hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+				if err := RegisterEventHandlerTo[DummyEvent1](failing, hn); err != nil {
+					t.Fatalf("register handler: %v", err)
+				}
+				_ = NewEventNotifier[DummyEvent1](failing).Notify(ctx, DummyEvent1{})
+				if _, err := RedriveTo(context.Background(), m, q); err != nil {
+					t.Fatalf("redrive: %v", err)
+				}
+			},
+		},
+		{
+			name: "event store",
+			transmit: func(t *testing.T, m *Mob) {
+				s, err := NewFileEventStore(m, filepath.Join(t.TempDir(), "events.jsonl"), JSONCodec{})
+				if err != nil {
+					t.Fatalf("new file event store: %v", err)
+				}
+				defer s.Close()
+				if err := RecordEventsTo(m, s, nil)(ctx, DummyEvent1{}, func(context.Context, interface{}) error { return nil }); err != nil {
+					t.Fatalf("record: %v", err)
+				}
+				if _, err := ReplayTo(context.Background(), m, s, ReplayFilter{}); err != nil {
+					t.Fatalf("replay: %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, got := newMob(t)
+			tt.transmit(t, m)
+			select {
+			case md := <-got:
+				if !reflect.DeepEqual(md, want) {
+					t.Errorf("want %v, got %v", want, md)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("want event notified")
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package mob
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of handling of messages reported to Metrics.
+const (
+	// MetricSend is a kind of handling of a request sent with Send.
+	MetricSend = "send"
+	// MetricGather is a kind of handling of a request sent with Gather by one of gather handlers.
+	MetricGather = "gather"
+	// MetricNotify is a kind of handling of an event by one of event handlers.
+	MetricNotify = "notify"
+)
+
+// MetricLabels identify handling of messages of a single type by a single handler.
+type MetricLabels struct {
+	// Kind is a kind of handling, e.g. MetricSend.
+	Kind string
+	// Type is a name of a message's type in the Mob's type registry.
+	Type string
+	// Handler is a name of a handler, empty if the handler is unnamed.
+	Handler string
+}
+
+// Metrics provides an interface for instrumentation of handlers of a Mob instance.
+// Its methods are called concurrently.
+type Metrics interface {
+	// Begin is called when a handler starts handling a message.
+	Begin(l MetricLabels)
+	// End is called when a handler finishes handling a message with a duration of handling
+	// and an error returned by the handler, if any.
+	End(l MetricLabels, d time.Duration, err error)
+}
+
+// SetMetricsTo sets Metrics instrumenting handlers of the given Mob instance.
+// Durations of Send include interceptors. By default, handlers aren't instrumented.
+func SetMetricsTo(m *Mob, metrics Metrics) {
+	m.metrics = metrics
+}
+
+// SetMetrics sets Metrics instrumenting handlers of the global Mob instance.
+func SetMetrics(metrics Metrics) {
+	SetMetricsTo(m, metrics)
+}
+
+// nopEnd is returned by instrument if the Mob instance has no Metrics.
+func nopEnd(error) {}
+
+// instrument reports to the Mob's Metrics that a given handler starts handling a given message.
+// The returned function reports the end of handling.
+func instrument(m *Mob, kind string, msg interface{}, hn *handler) func(error) {
+	if m.metrics == nil {
+		return nopEnd
+	}
+	metrics := m.metrics
+	l := MetricLabels{Kind: kind, Type: TypeNameTo(m, msg), Handler: hn.name}
+	start := m.clock.Now()
+	metrics.Begin(l)
+	return func(err error) {
+		metrics.End(l, m.clock.Now().Sub(start), err)
+	}
+}
+
+// DefaultBuckets are upper bounds, in seconds, of buckets of a PrometheusMetrics' latency histogram.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics are Metrics kept in memory and exposed in the Prometheus text format.
+// It's an http.Handler serving the metrics:
+//
+//	mob_handler_calls_total        counter of handled messages
+//	mob_handler_errors_total       counter of failed messages
+//	mob_handler_duration_seconds   histogram of durations of handling
+//	mob_handler_in_flight          gauge of messages being handled
+//
+// All metrics are labeled with a kind, a type and a handler.
+type PrometheusMetrics struct {
+	buckets []float64
+	mu      sync.Mutex
+	series  map[MetricLabels]*metricSeries
+}
+
+// A metricSeries holds metrics of a single set of labels.
+type metricSeries struct {
+	calls    uint64
+	errors   uint64
+	inFlight int64
+	// counts are cumulative counts of durations per bucket, the last one is +Inf.
+	counts []uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics returns PrometheusMetrics with latency histogram buckets of given upper bounds in seconds.
+// If no buckets are given, DefaultBuckets are used.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	bs := make([]float64, len(buckets))
+	copy(bs, buckets)
+	sort.Float64s(bs)
+	return &PrometheusMetrics{buckets: bs, series: map[MetricLabels]*metricSeries{}}
+}
+
+// Begin increments a number of messages in flight.
+func (p *PrometheusMetrics) Begin(l MetricLabels) {
+	p.mu.Lock()
+	p.get(l).inFlight++
+	p.mu.Unlock()
+}
+
+// End records a handled message.
+func (p *PrometheusMetrics) End(l MetricLabels, d time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.get(l)
+	s.inFlight--
+	s.calls++
+	if err != nil {
+		s.errors++
+	}
+	sec := d.Seconds()
+	s.sum += sec
+	for i, b := range p.buckets {
+		if sec <= b {
+			s.counts[i]++
+		}
+	}
+	s.counts[len(p.buckets)]++
+}
+
+// get returns series of given labels. p.mu must be held.
+func (p *PrometheusMetrics) get(l MetricLabels) *metricSeries {
+	s, ok := p.series[l]
+	if !ok {
+		s = &metricSeries{counts: make([]uint64, len(p.buckets)+1)}
+		p.series[l] = s
+	}
+	return s
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	p.write(bw)
+	_ = bw.Flush()
+}
+
+func (p *PrometheusMetrics) write(w *bufio.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ls := make([]MetricLabels, 0, len(p.series))
+	for l := range p.series {
+		ls = append(ls, l)
+	}
+	sort.Slice(ls, func(i, j int) bool {
+		if ls[i].Kind != ls[j].Kind {
+			return ls[i].Kind < ls[j].Kind
+		}
+		if ls[i].Type != ls[j].Type {
+			return ls[i].Type < ls[j].Type
+		}
+		return ls[i].Handler < ls[j].Handler
+	})
+	w.WriteString("# HELP mob_handler_calls_total Number of messages handled.\n# TYPE mob_handler_calls_total counter\n")
+	for _, l := range ls {
+		fmt.Fprintf(w, "mob_handler_calls_total{%s} %d\n", promLabels(l), p.series[l].calls)
+	}
+	w.WriteString("# HELP mob_handler_errors_total Number of messages handlers failed to handle.\n# TYPE mob_handler_errors_total counter\n")
+	for _, l := range ls {
+		fmt.Fprintf(w, "mob_handler_errors_total{%s} %d\n", promLabels(l), p.series[l].errors)
+	}
+	w.WriteString("# HELP mob_handler_duration_seconds Duration of handling of messages.\n# TYPE mob_handler_duration_seconds histogram\n")
+	for _, l := range ls {
+		s, pl := p.series[l], promLabels(l)
+		for i, b := range p.buckets {
+			fmt.Fprintf(w, "mob_handler_duration_seconds_bucket{%s,le=\"%s\"} %d\n", pl, promFloat(b), s.counts[i])
+		}
+		fmt.Fprintf(w, "mob_handler_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", pl, s.counts[len(p.buckets)])
+		fmt.Fprintf(w, "mob_handler_duration_seconds_sum{%s} %s\n", pl, promFloat(s.sum))
+		fmt.Fprintf(w, "mob_handler_duration_seconds_count{%s} %d\n", pl, s.calls)
+	}
+	w.WriteString("# HELP mob_handler_in_flight Number of messages being handled.\n# TYPE mob_handler_in_flight gauge\n")
+	for _, l := range ls {
+		fmt.Fprintf(w, "mob_handler_in_flight{%s} %d\n", promLabels(l), p.series[l].inFlight)
+	}
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabels(l MetricLabels) string {
+	return `kind="` + promEscaper.Replace(l.Kind) + `",type="` + promEscaper.Replace(l.Type) + `",handler="` + promEscaper.Replace(l.Handler) + `"`
+}
+
+func promFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
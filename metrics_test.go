@@ -0,0 +1,139 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type metricCall struct {
+	l   MetricLabels
+	d   time.Duration
+	err error
+}
+
+type recordingMetrics struct {
+	mu    sync.Mutex
+	begun []MetricLabels
+	ended []metricCall
+}
+
+func (r *recordingMetrics) Begin(l MetricLabels) {
+	r.mu.Lock()
+	r.begun = append(r.begun, l)
+	r.mu.Unlock()
+}
+
+func (r *recordingMetrics) End(l MetricLabels, d time.Duration, err error) {
+	r.mu.Lock()
+	r.ended = append(r.ended, metricCall{l: l, d: d, err: err})
+	r.mu.Unlock()
+}
+
+func TestSetMetrics(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetClockTo(m, c)
+	r := &recordingMetrics{}
+	SetMetricsTo(m, r)
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(context.Context, DummyRequest1) (DummyResponse1, error) {
+		c.Advance(time.Second)
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf, WithName("Request")); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	if err := RegisterGatherHandlerTo[DummyRequest2, DummyResponse1](m, DummyRequestHandler4{}, WithName("Gather")); err != nil {
+		t.Fatalf("register gather handler: %v", err)
+	}
+	failing := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, failing, WithName("Failing")); err != nil {
+		t.Fatalf("register failing event handler: %v", err)
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := NewRequestGatherer[DummyRequest2, DummyResponse1](m).Gather(context.Background(), DummyRequest2{}, GatherAll()); err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err == nil {
+		t.Fatal("want err, got nil")
+	}
+	if len(r.begun) != 4 || len(r.ended) != 4 {
+		t.Fatalf("want 4 handlers instrumented, got %v and %v", r.begun, r.ended)
+	}
+	sort.Slice(r.ended, func(i, j int) bool { return r.ended[i].l.Handler < r.ended[j].l.Handler })
+	want := []metricCall{
+		{l: MetricLabels{Kind: MetricNotify, Type: "github.com/erni27/mob.DummyEvent1"}},
+		{l: MetricLabels{Kind: MetricNotify, Type: "github.com/erni27/mob.DummyEvent1", Handler: "Failing"}, err: errors.New("dummy")},
+		{l: MetricLabels{Kind: MetricGather, Type: "github.com/erni27/mob.DummyRequest2", Handler: "Gather"}},
+		{l: MetricLabels{Kind: MetricSend, Type: "github.com/erni27/mob.DummyRequest1", Handler: "Request"}, d: time.Second},
+	}
+	for i, w := range want {
+		got := r.ended[i]
+		if got.l != w.l || got.d != w.d || (got.err == nil) != (w.err == nil) {
+			t.Errorf("want %v, got %v", w, got)
+		}
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	p := NewPrometheusMetrics(0.1, 1)
+	l := MetricLabels{Kind: MetricSend, Type: `a"b\c`, Handler: "Dummy"}
+	p.Begin(l)
+	p.End(l, 50*time.Millisecond, nil)
+	p.Begin(l)
+	p.End(l, 2*time.Second, errors.New("dummy"))
+	p.Begin(MetricLabels{Kind: MetricNotify, Type: "Event"})
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	res, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("want Prometheus text format, got %s", ct)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	want := `# HELP mob_handler_calls_total Number of messages handled.
+# TYPE mob_handler_calls_total counter
+mob_handler_calls_total{kind="notify",type="Event",handler=""} 0
+mob_handler_calls_total{kind="send",type="a\"b\\c",handler="Dummy"} 2
+# HELP mob_handler_errors_total Number of messages handlers failed to handle.
+# TYPE mob_handler_errors_total counter
+mob_handler_errors_total{kind="notify",type="Event",handler=""} 0
+mob_handler_errors_total{kind="send",type="a\"b\\c",handler="Dummy"} 1
+# HELP mob_handler_duration_seconds Duration of handling of messages.
+# TYPE mob_handler_duration_seconds histogram
+mob_handler_duration_seconds_bucket{kind="notify",type="Event",handler="",le="0.1"} 0
+mob_handler_duration_seconds_bucket{kind="notify",type="Event",handler="",le="1"} 0
+mob_handler_duration_seconds_bucket{kind="notify",type="Event",handler="",le="+Inf"} 0
+mob_handler_duration_seconds_sum{kind="notify",type="Event",handler=""} 0
+mob_handler_duration_seconds_count{kind="notify",type="Event",handler=""} 0
+mob_handler_duration_seconds_bucket{kind="send",type="a\"b\\c",handler="Dummy",le="0.1"} 1
+mob_handler_duration_seconds_bucket{kind="send",type="a\"b\\c",handler="Dummy",le="1"} 1
+mob_handler_duration_seconds_bucket{kind="send",type="a\"b\\c",handler="Dummy",le="+Inf"} 2
+mob_handler_duration_seconds_sum{kind="send",type="a\"b\\c",handler="Dummy"} 2.05
+mob_handler_duration_seconds_count{kind="send",type="a\"b\\c",handler="Dummy"} 2
+# HELP mob_handler_in_flight Number of messages being handled.
+# TYPE mob_handler_in_flight gauge
+mob_handler_in_flight{kind="notify",type="Event",handler=""} 1
+mob_handler_in_flight{kind="send",type="a\"b\\c",handler="Dummy"} 0
+`
+	if string(body) != want {
+		t.Errorf("want\n%s\ngot\n%s", want, body)
+	}
+}
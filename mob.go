@@ -3,8 +3,10 @@
 package mob
 
 import (
+	"context"
 	"errors"
 	"reflect"
+	"sync"
 )
 
 var m *Mob
@@ -15,14 +17,110 @@ func init() {
 
 // A Mob is a request / event handlers registry.
 type Mob struct {
-	interceptors []Interceptor
-	rhandlers    map[reqHnKey]*handler
-	ehandlers    map[reflect.Type][]*handler
+	// stats is accessed atomically, kept first for 64-bit alignment.
+	stats         dispatchStats
+	interceptors  []Interceptor
+	einterceptors []EventInterceptor
+	limiter       *limiter
+	// mu guards handlers and types registries.
+	mu        sync.RWMutex
+	rhandlers map[reqHnKey]*handler
+	ghandlers map[reqHnKey][]*handler
+	ehandlers map[reflect.Type][]*handler
+	// rsenders are type-erased senders of registered request types, one per response type.
+	rsenders map[reflect.Type][]func(ctx context.Context, req interface{}) (interface{}, error)
+	// enotifiers are type-erased notifiers of registered event types. A non-empty handler name restricts
+	// the notification to the handlers of that name.
+	enotifiers map[reflect.Type]func(ctx context.Context, event interface{}, handler string) error
+	// types and names map registered types to their names and vice versa.
+	types   map[string]reflect.Type
+	names   map[reflect.Type]string
+	dlq     DeadLetterQueue
+	clock   Clock
+	metrics Metrics
+	tracer  Tracer
+	errh    func(ctx context.Context, err error)
+	// bmu guards background tasks and the closed flag.
+	bmu        sync.Mutex
+	background map[stopper]token
+	closed     bool
 }
 
 // New returns an initialized Mob instance.
 func New() *Mob {
-	return &Mob{rhandlers: map[reqHnKey]*handler{}, ehandlers: map[reflect.Type][]*handler{}}
+	return &Mob{
+		rhandlers:  map[reqHnKey]*handler{},
+		ghandlers:  map[reqHnKey][]*handler{},
+		ehandlers:  map[reflect.Type][]*handler{},
+		rsenders:   map[reflect.Type][]func(context.Context, interface{}) (interface{}, error){},
+		enotifiers: map[reflect.Type]func(context.Context, interface{}, string) error{},
+		types:      map[string]reflect.Type{},
+		names:      map[reflect.Type]string{},
+		clock:      systemClock{},
+		background: map[stopper]token{},
+	}
+}
+
+// Close stops all background tasks of the Mob instance, such as scheduled notifications.
+// Background tasks can't be started after the Mob instance is closed, ErrClosed is returned instead.
+// Requests and events can still be dispatched. Close is idempotent.
+func (m *Mob) Close() {
+	m.bmu.Lock()
+	m.closed = true
+	tasks := m.background
+	m.background = map[stopper]token{}
+	m.bmu.Unlock()
+	for t := range tasks {
+		t.stop()
+	}
+}
+
+// Close stops all background tasks of the global Mob instance.
+func Close() {
+	m.Close()
+}
+
+// SetErrorHandlerTo sets a function handling errors of the given Mob instance's handlers
+// executed asynchronously, so their errors can't be returned to a caller.
+// By default, such errors are discarded.
+func SetErrorHandlerTo(m *Mob, h func(ctx context.Context, err error)) {
+	m.errh = h
+}
+
+// SetErrorHandler sets a function handling errors of the global Mob instance's handlers
+// executed asynchronously, so their errors can't be returned to a caller.
+// By default, such errors are discarded.
+func SetErrorHandler(h func(ctx context.Context, err error)) {
+	SetErrorHandlerTo(m, h)
+}
+
+func handleError(ctx context.Context, m *Mob, err error) {
+	if m.errh != nil {
+		m.errh(ctx, err)
+	}
+}
+
+// A stopper is a background task of a Mob instance.
+type stopper interface {
+	stop()
+}
+
+// startBackground tracks a given background task until it's stopped or the Mob instance is closed.
+func startBackground(m *Mob, s stopper) error {
+	m.bmu.Lock()
+	defer m.bmu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	m.background[s] = token{}
+	return nil
+}
+
+// stopBackground stops tracking a given background task.
+func stopBackground(m *Mob, s stopper) {
+	m.bmu.Lock()
+	delete(m.background, s)
+	m.bmu.Unlock()
 }
 
 var (
@@ -37,11 +135,41 @@ var (
 	// unmarshal to a given type.
 	// It happens when a request or a response type is modified in the request processing pipeline.
 	ErrUnmarshal = errors.New("mob: failed to unmarshal")
+	// ErrInvalidOption indicates that a given option cannot be applied to a handler.
+	ErrInvalidOption = errors.New("mob: invalid option")
+	// ErrRateLimited indicates that a rate limit is exceeded.
+	ErrRateLimited = errors.New("mob: rate limited")
+	// ErrQuorumExceeded indicates that a gather quorum exceeds the number of registered handlers.
+	ErrQuorumExceeded = errors.New("mob: quorum exceeds number of handlers")
+	// ErrHandlerTimeout indicates that a handler exceeded its timeout.
+	ErrHandlerTimeout = errors.New("mob: handler timeout")
+	// ErrClosed indicates that a Mob instance is closed.
+	ErrClosed = errors.New("mob: closed")
+	// ErrNoUnitOfWork indicates that an event is raised outside of a unit of work.
+	ErrNoUnitOfWork = errors.New("mob: no unit of work")
+	// ErrUnknownType indicates that no type is registered under a given name.
+	ErrUnknownType = errors.New("mob: unknown type")
+	// ErrDuplicateType indicates that a type name is already taken by another type.
+	ErrDuplicateType = errors.New("mob: duplicate type")
+	// ErrAmbiguousRequest indicates that a request of a type unknown at compile time can't be sent,
+	// because handlers with different response types are registered for its type.
+	ErrAmbiguousRequest = errors.New("mob: ambiguous request")
 )
 
 type handler struct {
-	name     string
-	embedded interface{}
+	name         string
+	embedded     interface{}
+	singleflight bool
+	// singleflightKeys are metadata keys whose values are a part of a singleflight key.
+	singleflightKeys []string
+	limit            *rateLimit
+	fallback         interface{}
+	filter           interface{}
+	coalescing       interface{}
+	retry            *retry
+	once             bool
+	// fired is set atomically when a one-shot handler claims an event.
+	fired int32
 }
 
 // An AggregateHandlerError is a type alias for a slice of handler errors. It applies only to event handlers.
@@ -83,3 +211,17 @@ var nilable map[reflect.Kind]token = map[reflect.Kind]token{
 	reflect.Chan:  {},
 	reflect.Slice: {},
 }
+
+// typeName returns a name of a given type qualified with its package path.
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	if t.Kind() == reflect.Ptr {
+		return "*" + typeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
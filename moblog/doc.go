@@ -0,0 +1,3 @@
+// Package moblog provides interceptors logging requests and events dispatched by a mob instance
+// with a log/slog logger. It requires Go 1.21, the mob package itself requires Go 1.18 only.
+package moblog
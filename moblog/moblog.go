@@ -0,0 +1,156 @@
+//go:build go1.21
+
+package moblog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/erni27/mob"
+)
+
+// Options configures logging interceptors.
+type Options struct {
+	// Level is a level of records of successfully handled messages. If nil, slog.LevelInfo is used.
+	Level slog.Leveler
+	// ErrorLevel is a level of records of failed messages. If nil, slog.LevelError is used.
+	ErrorLevel slog.Leveler
+	// Sample, if not nil, decides whether a successfully handled message is logged.
+	// Failed messages are always logged.
+	Sample func(ctx context.Context, msg interface{}) bool
+	// Payload enables logging of requests, responses and events.
+	Payload bool
+	// Redact, if not nil, is called with a logged request, response or event and returns a value logged
+	// in its place, e.g. a copy without sensitive fields.
+	Redact func(v interface{}) interface{}
+	// Clock measures durations of handling. If nil, the system clock is used.
+	Clock mob.Clock
+}
+
+// SampleEvery returns an Options' Sample function which logs every n-th message.
+func SampleEvery(n int) func(ctx context.Context, msg interface{}) bool {
+	var seq uint64
+	return func(context.Context, interface{}) bool {
+		return n <= 1 || atomic.AddUint64(&seq, 1)%uint64(n) == 1
+	}
+}
+
+// RequestsTo returns an Interceptor which logs requests sent to the given Mob instance with a given logger.
+// A record carries a name of the request's type in the Mob's type registry, a handler's name, message IDs,
+// a duration of handling, an outcome and an error, if any. Errors are returned unchanged.
+func RequestsTo(m *mob.Mob, l *slog.Logger, o Options) mob.Interceptor {
+	return requests(func(msg interface{}) string { return mob.TypeNameTo(m, msg) }, l, o)
+}
+
+// Requests returns an Interceptor which logs requests sent to the global Mob instance with a given logger.
+func Requests(l *slog.Logger, o Options) mob.Interceptor {
+	return requests(mob.TypeName, l, o)
+}
+
+func requests(typeName func(msg interface{}) string, l *slog.Logger, o Options) mob.Interceptor {
+	return func(ctx context.Context, req interface{}, invoker mob.SendInvoker) (interface{}, error) {
+		start := o.now()
+		res, err := invoker(ctx, req)
+		level, ok := o.level(ctx, req, err)
+		if !ok || !l.Enabled(ctx, level) {
+			return res, err
+		}
+		attrs := o.attrs(ctx, typeName(req), start, err)
+		if name := mob.HandlerName(ctx); name != "" {
+			attrs = append(attrs, slog.String("handler", name))
+		}
+		if o.Payload {
+			attrs = append(attrs, slog.Any("request", o.redact(req)))
+			if err == nil {
+				attrs = append(attrs, slog.Any("response", o.redact(res)))
+			}
+		}
+		l.LogAttrs(ctx, level, "mob: send", attrs...)
+		return res, err
+	}
+}
+
+// EventsTo returns an EventInterceptor which logs events notified to the given Mob instance with a given logger.
+// A record carries a name of the event's type in the Mob's type registry, message IDs, a duration of handling,
+// an outcome and an error, if any. Errors are returned unchanged.
+//
+// A record is logged once per event, after all its handlers are executed, so it carries no handler's name.
+// Only errors of failed named handlers are prefixed with their names.
+func EventsTo(m *mob.Mob, l *slog.Logger, o Options) mob.EventInterceptor {
+	return events(func(msg interface{}) string { return mob.TypeNameTo(m, msg) }, l, o)
+}
+
+// Events returns an EventInterceptor which logs events notified to the global Mob instance with a given logger.
+func Events(l *slog.Logger, o Options) mob.EventInterceptor {
+	return events(mob.TypeName, l, o)
+}
+
+func events(typeName func(msg interface{}) string, l *slog.Logger, o Options) mob.EventInterceptor {
+	return func(ctx context.Context, event interface{}, invoker mob.NotifyInvoker) error {
+		start := o.now()
+		err := invoker(ctx, event)
+		level, ok := o.level(ctx, event, err)
+		if !ok || !l.Enabled(ctx, level) {
+			return err
+		}
+		attrs := o.attrs(ctx, typeName(event), start, err)
+		if o.Payload {
+			attrs = append(attrs, slog.Any("event", o.redact(event)))
+		}
+		l.LogAttrs(ctx, level, "mob: notify", attrs...)
+		return err
+	}
+}
+
+func (o Options) now() time.Time {
+	if o.Clock == nil {
+		return time.Now()
+	}
+	return o.Clock.Now()
+}
+
+// level returns a level of a record of a message handled with a given error, false if the message isn't sampled.
+func (o Options) level(ctx context.Context, msg interface{}, err error) (slog.Level, bool) {
+	if err != nil {
+		if o.ErrorLevel == nil {
+			return slog.LevelError, true
+		}
+		return o.ErrorLevel.Level(), true
+	}
+	if o.Sample != nil && !o.Sample(ctx, msg) {
+		return 0, false
+	}
+	if o.Level == nil {
+		return slog.LevelInfo, true
+	}
+	return o.Level.Level(), true
+}
+
+func (o Options) redact(v interface{}) interface{} {
+	if o.Redact == nil {
+		return v
+	}
+	return o.Redact(v)
+}
+
+// attrs returns attributes common to records of requests and events.
+func (o Options) attrs(ctx context.Context, typeName string, start time.Time, err error) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 9)
+	attrs = append(attrs, slog.String("type", typeName))
+	if id := mob.MessageID(ctx); id != "" {
+		attrs = append(attrs, slog.String("message_id", id))
+	}
+	if id := mob.CausationID(ctx); id != "" {
+		attrs = append(attrs, slog.String("causation_id", id))
+	}
+	if id := mob.CorrelationID(ctx); id != "" {
+		attrs = append(attrs, slog.String("correlation_id", id))
+	}
+	attrs = append(attrs, slog.Duration("duration", o.now().Sub(start)))
+	if err != nil {
+		return append(attrs, slog.String("outcome", "error"), slog.String("error", err.Error()))
+	}
+	return append(attrs, slog.String("outcome", "ok"))
+}
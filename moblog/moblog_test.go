@@ -0,0 +1,195 @@
+//go:build go1.21
+
+package moblog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erni27/mob"
+)
+
+type DummyRequest struct {
+	String string
+}
+
+type DummyResponse struct {
+	Int int
+}
+
+type DummyEvent struct {
+	Int int
+}
+
+// logRecords decodes records written by a slog.JSONHandler.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var recs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode record: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestRequests(t *testing.T) {
+	m := mob.New()
+	c := mob.NewManualClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	mob.AddInterceptorTo(m, RequestsTo(m, newTestLogger(&buf), Options{
+		Level:   slog.LevelDebug,
+		Payload: true,
+		Redact: func(v interface{}) interface{} {
+			if req, ok := v.(DummyRequest); ok {
+				req.String = "[redacted]"
+				return req
+			}
+			return v
+		},
+		Clock: c,
+	}))
+	var rhf mob.RequestHandlerFunc[DummyRequest, DummyResponse] = func(_ context.Context, req DummyRequest) (DummyResponse, error) {
+		c.Advance(time.Second)
+		if req.String == "fail" {
+			return DummyResponse{}, errors.New("dummy")
+		}
+		return DummyResponse{Int: 997}, nil
+	}
+	if err := mob.RegisterRequestHandlerTo[DummyRequest, DummyResponse](m, rhf, mob.WithName("Dummy")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	s := mob.NewRequestSender[DummyRequest, DummyResponse](m)
+	if _, err := s.Send(context.Background(), DummyRequest{String: "secret"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := s.Send(context.Background(), DummyRequest{String: "fail"}); err == nil || err.Error() != "Dummy: dummy" {
+		t.Fatalf("want handler's error returned, got %v", err)
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 2 {
+		t.Fatalf("want 2 records, got %v", recs)
+	}
+	ok, failed := recs[0], recs[1]
+	want := map[string]interface{}{
+		"level":    "DEBUG",
+		"msg":      "mob: send",
+		"type":     "github.com/erni27/mob/moblog.DummyRequest",
+		"handler":  "Dummy",
+		"duration": float64(time.Second),
+		"outcome":  "ok",
+	}
+	for k, v := range want {
+		if ok[k] != v {
+			t.Errorf("want %s %v, got %v", k, v, ok[k])
+		}
+	}
+	if ok["message_id"] == nil || ok["message_id"] != ok["correlation_id"] {
+		t.Errorf("want message and correlation IDs, got %v", ok)
+	}
+	if req := ok["request"].(map[string]interface{}); req["String"] != "[redacted]" {
+		t.Errorf("want request redacted, got %v", req)
+	}
+	if res := ok["response"].(map[string]interface{}); res["Int"] != float64(997) {
+		t.Errorf("want response logged, got %v", res)
+	}
+	if failed["level"] != "ERROR" || failed["outcome"] != "error" || failed["error"] != "dummy" {
+		t.Errorf("want error record, got %v", failed)
+	}
+	if _, ok := failed["response"]; ok {
+		t.Errorf("want no response of a failed request, got %v", failed)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	m := mob.New()
+	var buf bytes.Buffer
+	mob.AddEventInterceptorTo(m, EventsTo(m, newTestLogger(&buf), Options{Sample: SampleEvery(2), ErrorLevel: slog.LevelWarn}))
+	var ehf mob.EventHandlerFunc[DummyEvent] = func(_ context.Context, ev DummyEvent) error {
+		if ev.Int < 0 {
+			return errors.New("dummy")
+		}
+		return nil
+	}
+	if err := mob.RegisterEventHandlerTo[DummyEvent](m, ehf, mob.WithName("Dummy")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	nf := mob.NewEventNotifier[DummyEvent](m)
+	for i := 1; i <= 4; i++ {
+		if err := nf.Notify(context.Background(), DummyEvent{Int: i}); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+	if err := nf.Notify(context.Background(), DummyEvent{Int: -1}); err == nil {
+		t.Fatal("want err, got nil")
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 3 {
+		t.Fatalf("want 2 sampled records and an error record, got %v", recs)
+	}
+	if rec := recs[0]; rec["level"] != "INFO" || rec["msg"] != "mob: notify" || rec["type"] != "github.com/erni27/mob/moblog.DummyEvent" || rec["outcome"] != "ok" {
+		t.Errorf("want event record, got %v", rec)
+	}
+	if _, ok := recs[0]["event"]; ok {
+		t.Errorf("want no payload logged, got %v", recs[0])
+	}
+	if rec := recs[2]; rec["level"] != "WARN" || !strings.Contains(rec["error"].(string), "Dummy: dummy") {
+		t.Errorf("want error record with handler's name, got %v", rec)
+	}
+}
+
+func TestRequests_Disabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	m := mob.New()
+	mob.AddInterceptorTo(m, RequestsTo(m, l, Options{}))
+	var rhf mob.RequestHandlerFunc[DummyRequest, DummyResponse] = func(context.Context, DummyRequest) (DummyResponse, error) {
+		return DummyResponse{}, nil
+	}
+	if err := mob.RegisterRequestHandlerTo[DummyRequest, DummyResponse](m, rhf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := mob.NewRequestSender[DummyRequest, DummyResponse](m).Send(context.Background(), DummyRequest{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("want nothing logged below the logger's level, got %s", buf.String())
+	}
+}
+
+func TestRequests_Global(t *testing.T) {
+	var buf bytes.Buffer
+	// The interceptor is invoked directly, so nothing is registered to the global Mob instance.
+	i := Requests(newTestLogger(&buf), Options{})
+	invoker := func(context.Context, interface{}) (interface{}, error) { return DummyResponse{}, nil }
+	if _, err := i(context.Background(), DummyRequest{}, invoker); err != nil {
+		t.Fatalf("intercept: %v", err)
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 1 || recs[0]["type"] != "github.com/erni27/mob/moblog.DummyRequest" {
+		t.Errorf("want request logged with its type, got %v", recs)
+	}
+}
@@ -0,0 +1,317 @@
+package mob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Operations of frames exchanged between a Broker and a NetBus.
+const (
+	busOpPublish = "publish"
+	busOpConsume = "consume"
+	busOpDeliver = "deliver"
+	busOpAck     = "ack"
+	busOpNack    = "nack"
+	busOpOK      = "ok"
+	busOpError   = "error"
+)
+
+// A busFrame is a frame exchanged between a Broker and a NetBus, encoded as a JSON line.
+type busFrame struct {
+	Op      string   `json:"op"`
+	Group   string   `json:"group,omitempty"`
+	Message *Message `json:"message,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// errNack indicates that a consumer didn't acknowledge a message.
+var errNack = errors.New("mob: message not acknowledged")
+
+// A Broker is a message broker serving NetBus clients over stream connections, e.g. TCP or Unix sockets.
+// Messages are kept in memory, routed and redelivered like by a LoopbackBus.
+type Broker struct {
+	bus *LoopbackBus
+	// mu guards listeners, conns and closed.
+	mu        sync.Mutex
+	listeners map[net.Listener]token
+	conns     map[net.Conn]token
+	closed    bool
+}
+
+// NewBroker returns a Broker without consumer groups.
+func NewBroker() *Broker {
+	return &Broker{
+		bus:       NewLoopbackBus(),
+		listeners: map[net.Listener]token{},
+		conns:     map[net.Conn]token{},
+	}
+}
+
+// SetRedelivery sets a delay before a message which isn't acknowledged is redelivered and a maximum number
+// of deliveries of a message to a consumer group, like LoopbackBus.SetRedelivery.
+func (b *Broker) SetRedelivery(delay time.Duration, maxDeliveries int) {
+	b.bus.SetRedelivery(delay, maxDeliveries)
+}
+
+// Serve accepts connections on a given listener and serves them until the Broker is closed.
+// It returns ErrClosed once the Broker is closed, or an error returned by the listener.
+func (b *Broker) Serve(l net.Listener) error {
+	if !b.track(l, nil) {
+		l.Close()
+		return ErrClosed
+	}
+	defer b.untrack(l, nil)
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			b.mu.Lock()
+			closed := b.closed
+			b.mu.Unlock()
+			if closed {
+				return ErrClosed
+			}
+			return err
+		}
+		if !b.track(nil, c) {
+			c.Close()
+			return ErrClosed
+		}
+		go b.serveConn(c)
+	}
+}
+
+// Close closes the Broker's listeners and connections. Messages not acknowledged are lost.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for l := range b.listeners {
+		l.Close()
+	}
+	for c := range b.conns {
+		c.Close()
+	}
+	return nil
+}
+
+func (b *Broker) track(l net.Listener, c net.Conn) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	if l != nil {
+		b.listeners[l] = token{}
+	}
+	if c != nil {
+		b.conns[c] = token{}
+	}
+	return true
+}
+
+func (b *Broker) untrack(l net.Listener, c net.Conn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.listeners, l)
+	delete(b.conns, c)
+}
+
+func (b *Broker) serveConn(c net.Conn) {
+	defer b.untrack(nil, c)
+	defer c.Close()
+	dec := json.NewDecoder(c)
+	enc := json.NewEncoder(c)
+	for {
+		var f busFrame
+		if err := dec.Decode(&f); err != nil {
+			return
+		}
+		switch {
+		case f.Op == busOpPublish && f.Message != nil:
+			if err := b.bus.Publish(context.Background(), *f.Message); err != nil {
+				_ = enc.Encode(busFrame{Op: busOpError, Error: err.Error()})
+				continue
+			}
+			if err := enc.Encode(busFrame{Op: busOpOK}); err != nil {
+				return
+			}
+		case f.Op == busOpConsume:
+			// The connection is dedicated to the consumer from now on.
+			b.serveConsumer(c, dec, enc, f.Group)
+			return
+		default:
+			_ = enc.Encode(busFrame{Op: busOpError, Error: "mob: unexpected frame " + f.Op})
+			return
+		}
+	}
+}
+
+// serveConsumer delivers messages of a given consumer group over a connection, one at a time.
+func (b *Broker) serveConsumer(c net.Conn, dec *json.Decoder, enc *json.Encoder, group string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	acks := make(chan bool)
+	go func() {
+		defer cancel()
+		for {
+			var f busFrame
+			if err := dec.Decode(&f); err != nil {
+				return
+			}
+			select {
+			case acks <- f.Op == busOpAck:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	_ = b.bus.Consume(ctx, group, func(ctx context.Context, msg Message) error {
+		if err := enc.Encode(busFrame{Op: busOpDeliver, Message: &msg}); err != nil {
+			cancel()
+			return err
+		}
+		select {
+		case ok := <-acks:
+			if !ok {
+				return errNack
+			}
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// A NetBus is an EventBus client of a Broker.
+type NetBus struct {
+	network string
+	addr    string
+	// mu guards the publishing connection.
+	mu  sync.Mutex
+	c   net.Conn
+	enc *json.Encoder
+	dec *json.Decoder
+}
+
+// DialBus returns a NetBus connected to a Broker listening on a given network address, e.g. "tcp" and "localhost:4222"
+// or "unix" and "/tmp/mob.sock". Each consumer uses its own connection.
+func DialBus(ctx context.Context, network, addr string) (*NetBus, error) {
+	b := &NetBus{network: network, addr: addr}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.dial(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// dial connects the publishing connection. It must be called with b.mu held.
+func (b *NetBus) dial(ctx context.Context) error {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, b.network, b.addr)
+	if err != nil {
+		return err
+	}
+	b.c, b.enc, b.dec = c, json.NewEncoder(c), json.NewDecoder(c)
+	return nil
+}
+
+// Publish publishes a message to all consumer groups. It returns once the Broker confirms the message.
+func (b *NetBus) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.c == nil {
+		if err := b.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = b.c.SetDeadline(dl)
+		defer func() {
+			if b.c != nil {
+				_ = b.c.SetDeadline(time.Time{})
+			}
+		}()
+	}
+	var res busFrame
+	err := b.enc.Encode(busFrame{Op: busOpPublish, Message: &msg})
+	if err == nil {
+		err = b.dec.Decode(&res)
+	}
+	if err != nil {
+		// The connection is in an unknown state, it's dialed again by the next Publish.
+		b.c.Close()
+		b.c = nil
+		return err
+	}
+	if res.Op != busOpOK {
+		return errors.New(res.Error)
+	}
+	return nil
+}
+
+// Consume delivers messages published to a given consumer group to f until the context is done.
+// A message is acknowledged if f returns nil, otherwise it's redelivered by the Broker.
+// If the connection to the Broker fails, its error is returned.
+func (b *NetBus) Consume(ctx context.Context, group string, f func(ctx context.Context, msg Message) error) error {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, b.network, b.addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-stop:
+		}
+	}()
+	enc := json.NewEncoder(c)
+	dec := json.NewDecoder(c)
+	if err := enc.Encode(busFrame{Op: busOpConsume, Group: group}); err != nil {
+		return consumeErr(ctx, err)
+	}
+	for {
+		var fr busFrame
+		if err := dec.Decode(&fr); err != nil {
+			return consumeErr(ctx, err)
+		}
+		if fr.Op != busOpDeliver || fr.Message == nil {
+			return errors.New("mob: unexpected frame " + fr.Op + " " + fr.Error)
+		}
+		op := busOpAck
+		if err := f(ctx, *fr.Message); err != nil {
+			op = busOpNack
+		}
+		if err := enc.Encode(busFrame{Op: op}); err != nil {
+			return consumeErr(ctx, err)
+		}
+	}
+}
+
+// consumeErr returns the context's error if the context is done, a given error otherwise.
+func consumeErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Close closes the publishing connection.
+func (b *NetBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.c == nil {
+		return nil
+	}
+	err := b.c.Close()
+	b.c = nil
+	return err
+}
@@ -0,0 +1,87 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNetBus(t *testing.T) {
+	tests := []struct {
+		network string
+		addr    func(t *testing.T) string
+	}{
+		{network: "tcp", addr: func(*testing.T) string { return "127.0.0.1:0" }},
+		{network: "unix", addr: func(t *testing.T) string { return filepath.Join(t.TempDir(), "mob.sock") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			l, err := net.Listen(tt.network, tt.addr(t))
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			b := NewBroker()
+			served := make(chan error)
+			go func() { served <- b.Serve(l) }()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			bus, err := DialBus(ctx, tt.network, l.Addr().String())
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer bus.Close()
+
+			m := New()
+			got := make(chan DummyEvent1)
+			fail := true
+			var hf EventHandlerFunc[DummyEvent1] = func(_ context.Context, ev DummyEvent1) error {
+				if fail {
+					fail = false
+					return errors.New("dummy")
+				}
+				got <- ev
+				return nil
+			}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, hf); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			cctx, ccancel := context.WithCancel(ctx)
+			consumed := make(chan error)
+			go func() { consumed <- ConsumeTo(cctx, m, bus, "g") }()
+			for {
+				b.bus.mu.Lock()
+				n := len(b.bus.groups)
+				b.bus.mu.Unlock()
+				if n == 1 {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if err := NewBusEventNotifier[DummyEvent1](m, bus).Notify(ctx, DummyEvent1{Int: 997}); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			select {
+			case ev := <-got:
+				if ev.Int != 997 {
+					t.Errorf("want event consumed, got %v", ev)
+				}
+			case <-ctx.Done():
+				t.Fatal("want nacked event redelivered")
+			}
+			ccancel()
+			if err := <-consumed; !errors.Is(err, context.Canceled) {
+				t.Errorf("want err %v, got %v", context.Canceled, err)
+			}
+			b.Close()
+			if err := <-served; !errors.Is(err, ErrClosed) {
+				t.Errorf("want err %v, got %v", ErrClosed, err)
+			}
+			if err := bus.Publish(ctx, Message{}); err == nil {
+				t.Error("want err publishing to closed broker, got nil")
+			}
+		})
+	}
+}
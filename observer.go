@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 // EventHandler provides an interface for an event handler.
@@ -39,10 +40,49 @@ type notifier[T any] struct {
 }
 
 func (nf *notifier[T]) Notify(ctx context.Context, event T) error {
+	return nf.notify(ctx, event, "")
+}
+
+// notify notifies a given event to the handlers of a given name, or to all handlers if the name is empty.
+func (nf *notifier[T]) notify(ctx context.Context, event T, name string) error {
+	nf.m.mu.RLock()
 	hns, ok := nf.m.ehandlers[reflect.TypeOf(event)]
+	nf.m.mu.RUnlock()
+	if ok && name != "" {
+		hns = named(hns, name)
+		ok = len(hns) > 0
+	}
 	if !ok {
 		return ErrHandlerNotFound
 	}
+	if nf.m.limiter != nil {
+		if err := nf.m.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	nf.m.stats.begin()
+	ctx, end := trace(withMessageID(ctx), nf.m, SpanNotify, event, nil)
+	var err error
+	if len(nf.m.einterceptors) == 0 {
+		err = dispatch(ctx, nf.m, hns, event)
+	} else {
+		invoker := func(ctx context.Context, cevent interface{}) error {
+			event, ok := cevent.(T)
+			if !ok {
+				return fmt.Errorf("%w: event is %T, want %T", ErrUnmarshal, cevent, event)
+			}
+			return dispatch(ctx, nf.m, hns, event)
+		}
+		err = chainEventInterceptors(nf.m.einterceptors)(ctx, event, invoker)
+	}
+	end(err)
+	nf.m.stats.end(err)
+	return err
+}
+
+// dispatch executes handlers matching a given event concurrently and collects their errors.
+func dispatch[T any](ctx context.Context, m *Mob, hns []*handler, event T) error {
+	hns = match(ctx, m, hns, event)
 	n := len(hns)
 	c := make(chan error)
 	var wg sync.WaitGroup
@@ -53,7 +93,13 @@ func (nf *notifier[T]) Notify(ctx context.Context, event T) error {
 			hn := hns[i]
 			// Dispatching result not checked because if a handler is found then it should always satisfy EventHandler[T] interface.
 			dhn, _ := hn.embedded.(EventHandler[T])
-			if err := dhn.Handle(ctx, event); err != nil {
+			ctx, endSpan := trace(ctx, m, SpanHandle, event, hn)
+			end := instrument(m, MetricNotify, event, hn)
+			err := dhn.Handle(ctx, event)
+			end(err)
+			endSpan(err)
+			if err != nil {
+				deadLetter(ctx, m, hn, event, err)
 				if hn.name != "" {
 					err = fmt.Errorf("%s: %w", hn.name, err)
 				}
@@ -75,22 +121,150 @@ func (nf *notifier[T]) Notify(ctx context.Context, event T) error {
 	return nil
 }
 
+// named returns handlers of a given name.
+func named(hns []*handler, name string) []*handler {
+	var matched []*handler
+	for _, hn := range hns {
+		if hn.name == name {
+			matched = append(matched, hn)
+		}
+	}
+	return matched
+}
+
+// match returns handlers which should handle a given event.
+// A handler registered with a filter matches only if the filter returns true.
+// A one-shot handler matches only if it claims the event, then it's unregistered.
+func match[T any](ctx context.Context, m *Mob, hns []*handler, event T) []*handler {
+	matched := make([]*handler, 0, len(hns))
+	for _, hn := range hns {
+		if hn.filter != nil {
+			// Assertion result not checked because the filter's type is validated during the registration.
+			if f, _ := hn.filter.(func(context.Context, T) bool); !f(ctx, event) {
+				continue
+			}
+		}
+		if hn.once {
+			if !atomic.CompareAndSwapInt32(&hn.fired, 0, 1) {
+				continue
+			}
+			unregisterEventHandler(m, reflect.TypeOf(event), hn)
+		}
+		matched = append(matched, hn)
+	}
+	return matched
+}
+
 // RegisterEventHandlerTo adds a given event handler to the given Mob instance.
 // Returns nil if the handler added successfully, an error otherwise.
 //
 // Multiple event handlers can be registered for a single event's type.
 func RegisterEventHandlerTo[T any](m *Mob, ehn EventHandler[T], opts ...Option) error {
+	_, err := registerEventHandler(m, ehn, opts)
+	return err
+}
+
+func registerEventHandler[T any](m *Mob, ehn EventHandler[T], opts []Option) (*handler, error) {
 	if !isValid(ehn) {
-		return ErrInvalidHandler
+		return nil, ErrInvalidHandler
 	}
 	var ev T
 	k := reflect.TypeOf(ev)
-	hn := &handler{embedded: ehn}
+	hn := &handler{}
 	for _, opt := range opts {
 		opt.apply(hn)
 	}
+	if hn.singleflight {
+		return nil, fmt.Errorf("%w: singleflight applies only to request handlers", ErrInvalidOption)
+	}
+	if hn.fallback != nil {
+		return nil, fmt.Errorf("%w: fallback applies only to request handlers", ErrInvalidOption)
+	}
+	if hn.filter != nil {
+		f, ok := hn.filter.(func(context.Context, T) bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: filter is %T, want %T", ErrInvalidOption, hn.filter, f)
+		}
+		if f == nil {
+			return nil, fmt.Errorf("%w: nil filter", ErrInvalidOption)
+		}
+	}
+	if hn.limit != nil {
+		if err := hn.limit.validate(); err != nil {
+			return nil, err
+		}
+		ehn = &rateLimitedEventHandler[T]{embedded: ehn, l: newLimiter(*hn.limit)}
+	}
+	if hn.retry != nil {
+		if err := hn.retry.validate(); err != nil {
+			return nil, err
+		}
+		ehn = &retryingEventHandler[T]{embedded: ehn, r: *hn.retry, m: m}
+	}
+	if hn.coalescing != nil {
+		cfg, ok := hn.coalescing.(*coalescing[T])
+		if !ok {
+			return nil, fmt.Errorf("%w: coalescing is %T, want %T", ErrInvalidOption, hn.coalescing, cfg)
+		}
+		cehn, err := newCoalescingEventHandler(m, hn, ehn, *cfg)
+		if err != nil {
+			return nil, err
+		}
+		ehn = cehn
+	}
+	hn.embedded = ehn
+	m.mu.Lock()
 	m.ehandlers[k] = append(m.ehandlers[k], hn)
-	return nil
+	registerType(m, k)
+	if _, ok := m.enotifiers[k]; !ok {
+		nf := &notifier[T]{m: m}
+		m.enotifiers[k] = func(ctx context.Context, event interface{}, handler string) error {
+			ev, ok := event.(T)
+			if !ok {
+				return fmt.Errorf("%w: event is %T, want %T", ErrUnmarshal, event, ev)
+			}
+			return nf.notify(ctx, ev, handler)
+		}
+	}
+	m.mu.Unlock()
+	return hn, nil
+}
+
+// notifyAny notifies an event of a type unknown at compile time to the given Mob instance.
+// The event's type must have been registered with an event handler, otherwise ErrHandlerNotFound is returned.
+func notifyAny(ctx context.Context, m *Mob, event interface{}) error {
+	return notifyHandler(ctx, m, event, "")
+}
+
+// notifyHandler notifies an event of a type unknown at compile time to the handlers of a given name,
+// or to all handlers if the name is empty. If no such handler is registered, ErrHandlerNotFound is returned.
+func notifyHandler(ctx context.Context, m *Mob, event interface{}, name string) error {
+	m.mu.RLock()
+	nf, ok := m.enotifiers[reflect.TypeOf(event)]
+	m.mu.RUnlock()
+	if !ok {
+		return ErrHandlerNotFound
+	}
+	return nf(ctx, event, name)
+}
+
+// unregisterEventHandler removes a given handler from the given Mob instance.
+// Handlers' slices are never modified in place, so the ones being notified aren't affected.
+func unregisterEventHandler(m *Mob, k reflect.Type, hn *handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hns := m.ehandlers[k]
+	rest := make([]*handler, 0, len(hns))
+	for _, h := range hns {
+		if h != hn {
+			rest = append(rest, h)
+		}
+	}
+	if len(rest) == 0 {
+		delete(m.ehandlers, k)
+		return
+	}
+	m.ehandlers[k] = rest
 }
 
 // RegisterEventHandler adds a given event handler to the global Mob instance.
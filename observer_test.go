@@ -5,6 +5,8 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -284,3 +286,137 @@ func TestNotify_Named(t *testing.T) {
 		})
 	}
 }
+
+func TestNotify_Filter(t *testing.T) {
+	defer clear()
+	tenant1 := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	tenant2 := &DummyEventHandler2{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	all := &DummyEventHandler3{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	byTenant := func(tenant string) func(context.Context, DummyEvent1) bool {
+		return func(_ context.Context, ev DummyEvent1) bool { return ev.String == tenant }
+	}
+	if err := RegisterEventHandler[DummyEvent1](tenant1, WithFilter(byTenant("tenant1"))); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](tenant2, WithFilter(byTenant("tenant2"))); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](all); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	for _, tenant := range []string{"tenant1", "tenant1", "tenant2", "tenant3"} {
+		if err := Notify(context.Background(), DummyEvent1{String: tenant}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	if calls := tenant1.Calls(); calls != 2 {
+		t.Errorf("want tenant1 handler called exactly 2, got %d", calls)
+	}
+	if calls := tenant2.Calls(); calls != 1 {
+		t.Errorf("want tenant2 handler called exactly 1, got %d", calls)
+	}
+	if calls := all.Calls(); calls != 4 {
+		t.Errorf("want unfiltered handler called exactly 4, got %d", calls)
+	}
+}
+
+func TestNotify_FilterNoneMatched(t *testing.T) {
+	defer clear()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandler[DummyEvent1](hn, WithFilter(func(context.Context, DummyEvent1) bool { return false })); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+	if calls := hn.Calls(); calls != 0 {
+		t.Errorf("want handler not called, got %d", calls)
+	}
+}
+
+func TestWithFilter_InvalidOption(t *testing.T) {
+	defer clear()
+	tests := []struct {
+		name     string
+		register func() error
+	}{
+		{
+			name: "mismatched filter",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithFilter(func(context.Context, string) bool { return true }))
+			},
+		},
+		{
+			name: "nil filter",
+			register: func() error {
+				return RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithFilter[DummyEvent1](nil))
+			},
+		},
+		{
+			name: "request handler",
+			register: func() error {
+				return RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithFilter(func(context.Context, DummyRequest1) bool { return true }))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.register(); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+		})
+	}
+}
+
+func TestNotify_Once(t *testing.T) {
+	defer clear()
+	once := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandler[DummyEvent1](once, WithOnce(), WithFilter(func(_ context.Context, ev DummyEvent1) bool { return ev.Int > 1 })); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	if calls := once.Calls(); calls != 1 {
+		t.Errorf("want one-shot handler called exactly 1, got %d", calls)
+	}
+	if err := Notify(context.Background(), DummyEvent1{Int: 3}); err != ErrHandlerNotFound {
+		t.Errorf("want error %v, got %v", ErrHandlerNotFound, err)
+	}
+}
+
+func TestNotify_OnceConcurrent(t *testing.T) {
+	defer clear()
+	var calls int32
+	var hf EventHandlerFunc[DummyEvent1] = func(context.Context, DummyEvent1) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+	if err := RegisterEventHandler[DummyEvent1](hf, WithOnce()); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = Notify(context.Background(), DummyEvent1{})
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("want one-shot handler called exactly 1, got %d", got)
+	}
+}
+
+func TestWithOnce_InvalidOption(t *testing.T) {
+	defer clear()
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithOnce()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+}
@@ -1,5 +1,10 @@
 package mob
 
+import (
+	"context"
+	"time"
+)
+
 // Option configures a handler during the registration process.
 type Option interface {
 	apply(*handler)
@@ -18,3 +23,104 @@ func WithName(name string) Option {
 	}
 	return opt
 }
+
+// WithSingleflight returns an Option that enables deduplication of concurrent, identical requests.
+// A request handler registered with this option executes only once for all requests equal to each other
+// that are sent while the execution is in flight. All callers receive the same response and error, or the same panic.
+// The execution's context carries the values of the first caller's context, but it's never canceled,
+// a caller whose context is done stops waiting and gets the context's error.
+//
+// Since callers share the first caller's context values, e.g. a tenant or a principal, requests are deduplicated
+// only if values of given metadata keys are equal as well. Keys identifying a caller must be listed, otherwise
+// a caller can receive a response computed on behalf of another one.
+//
+// The request type must be comparable. It applies only to request handlers.
+func WithSingleflight(metadataKeys ...string) Option {
+	var opt optionFunc = func(h *handler) {
+		h.singleflight = true
+		h.singleflightKeys = metadataKeys
+	}
+	return opt
+}
+
+// WithRateLimit returns an Option that limits the rate of a handler's invocations.
+// The limit is a token bucket allowing r invocations per second with bursts of at most burst invocations.
+// The mode determines whether an exceeding invocation waits for a token or fails with ErrRateLimited.
+func WithRateLimit(r float64, burst int, mode LimitMode) Option {
+	var opt optionFunc = func(h *handler) {
+		h.limit = &rateLimit{r: r, burst: burst, mode: mode}
+	}
+	return opt
+}
+
+// WithFallback returns an Option that associates a given fallback with a request handler.
+// The fallback's handler is invoked with the same request when the primary handler fails with one
+// of the fallback's errors or exceeds its timeout.
+//
+// The fallback must be configured for the same request-response pair as the primary handler.
+// It applies only to request handlers.
+func WithFallback[T any, U any](fb Fallback[T, U]) Option {
+	var opt optionFunc = func(h *handler) {
+		h.fallback = &fb
+	}
+	return opt
+}
+
+// WithFilter returns an Option that associates a given predicate with an event handler.
+// The handler is executed only for events the predicate returns true for.
+//
+// The predicate must accept the handler's event type. It applies only to event handlers.
+func WithFilter[T any](pred func(ctx context.Context, event T) bool) Option {
+	var opt optionFunc = func(h *handler) {
+		h.filter = pred
+	}
+	return opt
+}
+
+// WithOnce returns an Option that makes an event handler a one-shot handler.
+// A one-shot handler is executed for the first matching event only, then it's automatically unregistered.
+//
+// It applies only to event handlers.
+func WithOnce() Option {
+	var opt optionFunc = func(h *handler) {
+		h.once = true
+	}
+	return opt
+}
+
+// WithDebounce returns an Option that debounces an event handler. Events are grouped by a given key function,
+// a nil key function puts all events in a single group. A window of a group lasts until no event is notified
+// for a given duration. The handler is executed once per window, on a given edge.
+//
+// A handler executed on the trailing edge is executed asynchronously, its errors are dead-lettered and passed
+// to the Mob's error handler. Pending trailing-edge events are handled when the Mob is closed.
+// It applies only to event handlers.
+func WithDebounce[T any](window time.Duration, edge Edge, key func(event T) string) Option {
+	var opt optionFunc = func(h *handler) {
+		h.coalescing = &coalescing[T]{window: window, edge: edge, key: key, debounce: true}
+	}
+	return opt
+}
+
+// WithThrottle returns an Option that throttles an event handler. Events are grouped by a given key function,
+// a nil key function puts all events in a single group. A window of a group starts with its first event
+// and lasts for a given duration. The handler is executed once per window, on a given edge.
+//
+// A handler executed on the trailing edge is executed asynchronously, its errors are dead-lettered and passed
+// to the Mob's error handler. Pending trailing-edge events are handled when the Mob is closed.
+// It applies only to event handlers.
+func WithThrottle[T any](window time.Duration, edge Edge, key func(event T) string) Option {
+	var opt optionFunc = func(h *handler) {
+		h.coalescing = &coalescing[T]{window: window, edge: edge, key: key}
+	}
+	return opt
+}
+
+// WithRetry returns an Option that retries a failed handler. The handler is invoked at most a given number
+// of attempts. The backoff is a delay before the second attempt, it doubles with each next attempt.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	var opt optionFunc = func(h *handler) {
+		h.retry = &retry{attempts: attempts, backoff: backoff}
+	}
+	return opt
+}
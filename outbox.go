@@ -0,0 +1,477 @@
+package mob
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An OutboxEntry is an event staged in an outbox.
+type OutboxEntry struct {
+	// ID is a unique identifier of the entry.
+	ID string
+	// Event is a staged event.
+	Event interface{}
+	// EventType is a name of the event's type.
+	EventType string
+	// Time is the time the event is staged at.
+	Time time.Time
+	// Metadata is metadata of the context the event is staged with.
+	Metadata Metadata
+	// Attempts is a number of failed attempts to notify the event.
+	Attempts int
+}
+
+// DefaultOutboxMaxAttempts is a default maximum number of attempts to notify an outbox event.
+const DefaultOutboxMaxAttempts = 10
+
+// OutboxStore provides an interface for a storage of events staged to be notified.
+type OutboxStore interface {
+	// Add stores given entries.
+	Add(ctx context.Context, entries ...OutboxEntry) error
+	// Pending returns at most limit stored entries, the oldest first. An entry whose event can't be decoded,
+	// e.g. because its type is not registered, is returned with a nil Event.
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	// Remove removes entries with given IDs.
+	Remove(ctx context.Context, ids ...string) error
+	// Fail increments the number of failed attempts of entries with given IDs.
+	Fail(ctx context.Context, ids ...string) error
+}
+
+// StageTo adds given events to a given OutboxStore, so they're notified to the given Mob instance
+// by an outbox relay later on. To stage events within a unit of work, pass a store bound to the unit's transaction,
+// e.g. SQLOutboxStore.Tx.
+func StageTo(ctx context.Context, m *Mob, s OutboxStore, events ...interface{}) error {
+	entries := make([]OutboxEntry, 0, len(events))
+	now := m.clock.Now()
+	md := MetadataFrom(ctx)
+	for _, ev := range events {
+		if ev == nil {
+			return fmt.Errorf("%w: nil event", ErrInvalidOption)
+		}
+		entries = append(entries, OutboxEntry{
+			ID:        newID(),
+			Event:     ev,
+			EventType: TypeNameTo(m, ev),
+			Time:      now,
+			Metadata:  md,
+		})
+	}
+	return s.Add(ctx, entries...)
+}
+
+// Stage adds given events to a given OutboxStore, so they're notified to the global Mob instance
+// by an outbox relay later on.
+func Stage(ctx context.Context, s OutboxStore, events ...interface{}) error {
+	return StageTo(ctx, m, s, events...)
+}
+
+// RelayOutboxTo notifies at most limit pending events of a given OutboxStore to the given Mob instance
+// and removes the notified ones from the store. Events are notified with a context carrying metadata
+// of the context they're staged with. Events failing to be notified are kept in the store,
+// so they're notified again by the next relay, up to DefaultOutboxMaxAttempts times. Then they're removed
+// and dead-lettered by handlers, or by the Mob instance if no handler ran, e.g. because none is registered.
+// Handlers dead-letter an event only on its last attempt. Events are delivered at least once,
+// they're notified again if the relay fails to remove them.
+//
+// Returns the number of relayed events and an error, if any.
+func RelayOutboxTo(ctx context.Context, m *Mob, s OutboxStore, limit int) (int, error) {
+	return relayOutbox(ctx, m, s, limit, DefaultOutboxMaxAttempts)
+}
+
+func relayOutbox(ctx context.Context, m *Mob, s OutboxStore, limit, maxAttempts int) (int, error) {
+	if limit < 1 {
+		return 0, fmt.Errorf("%w: relay limit must be positive", ErrInvalidOption)
+	}
+	entries, err := s.Pending(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	var aggr AggregateHandlerError
+	var n int
+	ids := make([]string, 0, len(entries))
+	var failed []string
+	for _, e := range entries {
+		last := e.Attempts+1 >= maxAttempts
+		err := notifyEntry(ctx, m, e, last)
+		if err == nil {
+			n++
+			ids = append(ids, e.ID)
+			continue
+		}
+		aggr = append(aggr, fmt.Errorf("%s %s: %w", e.EventType, e.ID, err))
+		if last {
+			ids = append(ids, e.ID)
+		} else {
+			failed = append(failed, e.ID)
+		}
+	}
+	if len(failed) > 0 {
+		if err := s.Fail(ctx, failed...); err != nil {
+			aggr = append(aggr, err)
+		}
+	}
+	if len(ids) > 0 {
+		if err := s.Remove(ctx, ids...); err != nil {
+			return 0, err
+		}
+	}
+	if len(aggr) > 0 {
+		return n, aggr
+	}
+	return n, nil
+}
+
+// notifyEntry notifies an event of a given entry. Unless it's the entry's last attempt, failing handlers
+// don't dead-letter it. On the last attempt, the event is dead-lettered if no handler ran.
+func notifyEntry(ctx context.Context, m *Mob, e OutboxEntry, last bool) error {
+	if e.Event == nil {
+		return fmt.Errorf("%w: can't decode %s", ErrUnmarshal, e.EventType)
+	}
+	ctx = withMetadata(ctx, e.Metadata)
+	if !last {
+		ctx = withoutDeadLetters(ctx)
+	}
+	err := notifyAny(ctx, m, e.Event)
+	var aggr AggregateHandlerError
+	if err == nil || !last || m.dlq == nil || errors.As(err, &aggr) {
+		return err
+	}
+	dl := DeadLetter{
+		Event:     e.Event,
+		EventType: e.EventType,
+		Err:       err.Error(),
+		Attempts:  e.Attempts + 1,
+		Time:      m.clock.Now(),
+		Metadata:  MetadataFrom(ctx),
+	}
+	if perr := m.dlq.Put(ctx, dl); perr != nil {
+		handleError(ctx, m, fmt.Errorf("mob: dead letter: %w", perr))
+	}
+	return err
+}
+
+// RelayOutbox notifies at most limit pending events of a given OutboxStore to the global Mob instance
+// and removes the notified ones from the store.
+//
+// Returns the number of relayed events and an error, if any.
+func RelayOutbox(ctx context.Context, s OutboxStore, limit int) (int, error) {
+	return RelayOutboxTo(ctx, m, s, limit)
+}
+
+// An OutboxRelay configures a background relay of an outbox.
+type OutboxRelay struct {
+	// Store is a relayed OutboxStore. Required.
+	Store OutboxStore
+	// Interval is a duration between relays. Required.
+	Interval time.Duration
+	// BatchSize is a maximum number of events read from the store at once. Zero means 100.
+	BatchSize int
+	// MaxAttempts is a maximum number of attempts to notify an event. Zero means DefaultOutboxMaxAttempts.
+	MaxAttempts int
+	// OnError is called with an error returned by a relay. If nil, the error is passed to the Mob's error handler.
+	OnError func(err error)
+}
+
+// A Relaying is a handle of a background outbox relay.
+type Relaying struct {
+	m   *Mob
+	r   OutboxRelay
+	ctx context.Context
+	// mu guards timer and stopped.
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// Stop stops the background relay. A relay already running is not affected. Stop is idempotent.
+func (r *Relaying) Stop() {
+	r.stop()
+	stopBackground(r.m, r)
+}
+
+func (r *Relaying) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// schedule sets the timer for the next relay. It must be called with r.mu held.
+func (r *Relaying) schedule() {
+	r.timer = r.m.clock.AfterFunc(r.r.Interval, r.fire)
+}
+
+// fire relays pending events until the store has no more full batches, then schedules the next relay.
+func (r *Relaying) fire() {
+	for {
+		n, err := relayOutbox(r.ctx, r.m, r.r.Store, r.r.BatchSize, r.r.MaxAttempts)
+		if err != nil {
+			if r.r.OnError != nil {
+				r.r.OnError(err)
+			} else {
+				handleError(r.ctx, r.m, err)
+			}
+		}
+		if err != nil || n < r.r.BatchSize {
+			break
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopped {
+		r.schedule()
+	}
+}
+
+// StartOutboxRelayTo starts relaying pending events of an outbox to the given Mob instance in the background.
+// Events are notified with a context carrying the values of a given context, but never canceled.
+// The relay is stopped when the Mob instance is closed.
+//
+// If the Mob instance is closed, ErrClosed is returned.
+func StartOutboxRelayTo(ctx context.Context, m *Mob, or OutboxRelay) (*Relaying, error) {
+	if or.Store == nil || or.Interval <= 0 || or.BatchSize < 0 || or.MaxAttempts < 0 {
+		return nil, fmt.Errorf("%w: invalid outbox relay", ErrInvalidOption)
+	}
+	if or.BatchSize == 0 {
+		or.BatchSize = 100
+	}
+	if or.MaxAttempts == 0 {
+		or.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	r := &Relaying{m: m, r: or, ctx: detach(ctx)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := startBackground(m, r); err != nil {
+		return nil, err
+	}
+	r.schedule()
+	return r, nil
+}
+
+// StartOutboxRelay starts relaying pending events of an outbox to the global Mob instance in the background.
+//
+// If the global Mob instance is closed, ErrClosed is returned.
+func StartOutboxRelay(ctx context.Context, or OutboxRelay) (*Relaying, error) {
+	return StartOutboxRelayTo(ctx, m, or)
+}
+
+// A MemoryOutboxStore is an in-memory OutboxStore.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries []OutboxEntry
+}
+
+// NewMemoryOutboxStore returns an empty MemoryOutboxStore.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{}
+}
+
+// Add stores given entries.
+func (s *MemoryOutboxStore) Add(_ context.Context, entries ...OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+// Pending returns at most limit stored entries, the oldest first.
+func (s *MemoryOutboxStore) Pending(_ context.Context, limit int) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit > len(s.entries) {
+		limit = len(s.entries)
+	}
+	entries := make([]OutboxEntry, limit)
+	copy(entries, s.entries)
+	return entries, nil
+}
+
+// Remove removes entries with given IDs.
+func (s *MemoryOutboxStore) Remove(_ context.Context, ids ...string) error {
+	rm := make(map[string]token, len(ids))
+	for _, id := range ids {
+		rm[id] = token{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rest := s.entries[:0]
+	for _, e := range s.entries {
+		if _, ok := rm[e.ID]; !ok {
+			rest = append(rest, e)
+		}
+	}
+	s.entries = rest
+	return nil
+}
+
+// Fail increments the number of failed attempts of entries with given IDs.
+func (s *MemoryOutboxStore) Fail(_ context.Context, ids ...string) error {
+	failed := make(map[string]token, len(ids))
+	for _, id := range ids {
+		failed[id] = token{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.entries {
+		if _, ok := failed[s.entries[i].ID]; ok {
+			s.entries[i].Attempts++
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored entries.
+func (s *MemoryOutboxStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Placeholder is a style of SQL query parameters' placeholders.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion is a ? placeholder, used e.g. by MySQL and SQLite.
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar is a $1, $2, ... placeholder, used e.g. by PostgreSQL.
+	PlaceholderDollar
+)
+
+func (p Placeholder) format(n int) string {
+	if p == PlaceholderDollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// A sqlExecer is either *sql.DB or *sql.Tx.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// A SQLOutboxStore is an OutboxStore backed by a SQL database table. Pending entries are read with a LIMIT
+// clause, so it works with databases supporting it, e.g. PostgreSQL, MySQL and SQLite, but not e.g. SQL Server.
+// The table must have the following columns:
+//
+//	id         VARCHAR(32) PRIMARY KEY
+//	event_type VARCHAR(255) NOT NULL
+//	payload    BLOB NOT NULL (or an equivalent binary or text type)
+//	staged_at  BIGINT NOT NULL (Unix time in nanoseconds)
+//	metadata   TEXT NOT NULL (JSON object)
+//	attempts   INT NOT NULL
+//
+// Events are encoded as JSON.
+type SQLOutboxStore struct {
+	m     *Mob
+	db    sqlExecer
+	table string
+	ph    Placeholder
+}
+
+// NewSQLOutboxStore returns a SQLOutboxStore using a given table. Read events are decoded into types
+// registered in a given Mob instance's type registry.
+func NewSQLOutboxStore(m *Mob, db *sql.DB, table string, ph Placeholder) *SQLOutboxStore {
+	return &SQLOutboxStore{m: m, db: db, table: table, ph: ph}
+}
+
+// Tx returns a copy of the store operating within a given transaction. Events staged to the copy
+// are stored only if the transaction is committed.
+func (s *SQLOutboxStore) Tx(tx *sql.Tx) *SQLOutboxStore {
+	cp := *s
+	cp.db = tx
+	return &cp
+}
+
+// Add stores given entries.
+func (s *SQLOutboxStore) Add(ctx context.Context, entries ...OutboxEntry) error {
+	q := fmt.Sprintf("INSERT INTO %s (id, event_type, payload, staged_at, metadata, attempts) VALUES (%s, %s, %s, %s, %s, %s)",
+		s.table, s.ph.format(1), s.ph.format(2), s.ph.format(3), s.ph.format(4), s.ph.format(5), s.ph.format(6))
+	for _, e := range entries {
+		payload, err := json.Marshal(e.Event)
+		if err != nil {
+			return err
+		}
+		md, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, q, e.ID, e.EventType, payload, e.Time.UnixNano(), string(md), e.Attempts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns at most limit stored entries, the oldest first. An entry whose event or metadata can't be decoded,
+// e.g. because its type is not registered, is returned with a nil Event.
+func (s *SQLOutboxStore) Pending(ctx context.Context, limit int) ([]OutboxEntry, error) {
+	q := fmt.Sprintf("SELECT id, event_type, payload, staged_at, metadata, attempts FROM %s ORDER BY staged_at, id LIMIT %d", s.table, limit)
+	rows, err := s.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []OutboxEntry
+	for rows.Next() {
+		var e OutboxEntry
+		var payload []byte
+		var stagedAt int64
+		var md string
+		if err := rows.Scan(&e.ID, &e.EventType, &payload, &stagedAt, &md, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(0, stagedAt)
+		if err := json.Unmarshal([]byte(md), &e.Metadata); err == nil {
+			// A malformed entry is kept with a nil event, so the relay counts its attempts instead of stalling.
+			e.Event, _ = DecodeTo(s.m, JSONCodec{}, e.EventType, payload)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Remove removes entries with given IDs.
+func (s *SQLOutboxStore) Remove(ctx context.Context, ids ...string) error {
+	return s.execIn(ctx, "DELETE FROM %s WHERE id IN (%s)", ids)
+}
+
+// Fail increments the number of failed attempts of entries with given IDs.
+func (s *SQLOutboxStore) Fail(ctx context.Context, ids ...string) error {
+	return s.execIn(ctx, "UPDATE %s SET attempts = attempts + 1 WHERE id IN (%s)", ids)
+}
+
+// execIn executes a statement formatted with the store's table and placeholders of given IDs.
+func (s *SQLOutboxStore) execIn(ctx context.Context, format string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	phs := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		phs[i] = s.ph.format(i + 1)
+		args[i] = id
+	}
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(format, s.table, strings.Join(phs, ", ")), args...)
+	return err
+}
+
+// newID returns a random 128-bit identifier encoded as hex.
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("mob: generate id: " + err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
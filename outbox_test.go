@@ -0,0 +1,466 @@
+package mob
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// outboxDriver is a database/sql driver storing a single outbox table in memory.
+// It understands only the statements issued by SQLOutboxStore.
+type outboxDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*outboxDB
+}
+
+var testOutboxDriver = &outboxDriver{dbs: map[string]*outboxDB{}}
+
+func init() {
+	sql.Register("mobtest", testOutboxDriver)
+}
+
+type outboxRow struct {
+	id        string
+	eventType string
+	payload   []byte
+	stagedAt  int64
+	metadata  string
+	attempts  int64
+}
+
+type outboxDB struct {
+	mu      sync.Mutex
+	rows    map[string]outboxRow
+	queries []string
+}
+
+func (d *outboxDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &outboxDB{rows: map[string]outboxRow{}}
+		d.dbs[name] = db
+	}
+	return &outboxConn{db: db}, nil
+}
+
+// An outboxConn buffers writes of a transaction until it's committed.
+type outboxConn struct {
+	db *outboxDB
+	tx []func()
+}
+
+func (c *outboxConn) Prepare(query string) (driver.Stmt, error) {
+	return &outboxStmt{c: c, q: query}, nil
+}
+
+func (c *outboxConn) Close() error { return nil }
+
+func (c *outboxConn) Begin() (driver.Tx, error) {
+	c.tx = []func(){}
+	return c, nil
+}
+
+func (c *outboxConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for _, f := range c.tx {
+		f()
+	}
+	c.tx = nil
+	return nil
+}
+
+func (c *outboxConn) Rollback() error {
+	c.tx = nil
+	return nil
+}
+
+type outboxStmt struct {
+	c *outboxConn
+	q string
+}
+
+func (s *outboxStmt) Close() error  { return nil }
+func (s *outboxStmt) NumInput() int { return -1 }
+
+func (s *outboxStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.c.db
+	var f func()
+	switch {
+	case strings.HasPrefix(s.q, "INSERT INTO outbox "):
+		r := outboxRow{id: args[0].(string), eventType: args[1].(string), payload: args[2].([]byte), stagedAt: args[3].(int64), metadata: args[4].(string), attempts: args[5].(int64)}
+		f = func() { db.rows[r.id] = r }
+	case strings.HasPrefix(s.q, "UPDATE outbox SET attempts = attempts + 1 WHERE id IN "):
+		f = func() {
+			for _, id := range args {
+				r := db.rows[id.(string)]
+				r.attempts++
+				db.rows[r.id] = r
+			}
+		}
+	case strings.HasPrefix(s.q, "DELETE FROM outbox WHERE id IN "):
+		f = func() {
+			for _, id := range args {
+				delete(db.rows, id.(string))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", s.q)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries = append(db.queries, s.q)
+	if s.c.tx != nil {
+		s.c.tx = append(s.c.tx, f)
+	} else {
+		f()
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *outboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	const prefix = "SELECT id, event_type, payload, staged_at, metadata, attempts FROM outbox ORDER BY staged_at, id LIMIT "
+	if !strings.HasPrefix(s.q, prefix) {
+		return nil, fmt.Errorf("unexpected query %q", s.q)
+	}
+	limit, err := strconv.Atoi(strings.TrimPrefix(s.q, prefix))
+	if err != nil {
+		return nil, err
+	}
+	db := s.c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries = append(db.queries, s.q)
+	rows := make([]outboxRow, 0, len(db.rows))
+	for _, r := range db.rows {
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].stagedAt != rows[j].stagedAt {
+			return rows[i].stagedAt < rows[j].stagedAt
+		}
+		return rows[i].id < rows[j].id
+	})
+	if limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return &outboxRows{rows: rows}, nil
+}
+
+type outboxRows struct {
+	rows []outboxRow
+}
+
+func (r *outboxRows) Columns() []string {
+	return []string{"id", "event_type", "payload", "staged_at", "metadata", "attempts"}
+}
+
+func (r *outboxRows) Close() error { return nil }
+
+func (r *outboxRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	dest[0], dest[1], dest[2], dest[3], dest[4], dest[5] = row.id, row.eventType, row.payload, row.stagedAt, row.metadata, row.attempts
+	return nil
+}
+
+func openOutboxDB(t *testing.T) (*sql.DB, *outboxDB) {
+	db, err := sql.Open("mobtest", t.Name())
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping db: %v", err)
+	}
+	testOutboxDriver.mu.Lock()
+	defer testOutboxDriver.mu.Unlock()
+	return db, testOutboxDriver.dbs[t.Name()]
+}
+
+func TestRelayOutbox(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T, m *Mob) OutboxStore
+	}{
+		{
+			name: "memory",
+			store: func(*testing.T, *Mob) OutboxStore {
+				return NewMemoryOutboxStore()
+			},
+		},
+		{
+			name: "sql",
+			store: func(t *testing.T, m *Mob) OutboxStore {
+				db, _ := openOutboxDB(t)
+				return NewSQLOutboxStore(m, db, "outbox", PlaceholderQuestion)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			s := tt.store(t, m)
+			var got []DummyEvent1
+			var fail bool
+			hn := &DummyEventHandler1{handleFunc: func(_ context.Context, ev DummyEvent1) error {
+				if fail {
+					return errors.New("dummy")
+				}
+				got = append(got, ev)
+				return nil
+			}}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			evs := []interface{}{DummyEvent1{Int: 1}, DummyEvent1{Int: 2}, DummyEvent1{Int: 3}}
+			if err := StageTo(context.Background(), m, s, evs...); err != nil {
+				t.Fatalf("stage: %v", err)
+			}
+			if len(got) != 0 {
+				t.Fatalf("want no events notified before relay, got %v", got)
+			}
+			fail = true
+			n, err := RelayOutboxTo(context.Background(), m, s, 10)
+			if err == nil {
+				t.Error("want err, got nil")
+			}
+			if n != 0 {
+				t.Errorf("want no relayed events, got %d", n)
+			}
+			fail = false
+			n, err = RelayOutboxTo(context.Background(), m, s, 10)
+			if err != nil {
+				t.Fatalf("want success, got %v", err)
+			}
+			if n != 3 {
+				t.Errorf("want 3 relayed events, got %d", n)
+			}
+			if len(got) != 3 {
+				t.Fatalf("want 3 notified events, got %v", got)
+			}
+			pending, err := s.Pending(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("pending: %v", err)
+			}
+			if len(pending) != 0 {
+				t.Errorf("want no pending events, got %v", pending)
+			}
+		})
+	}
+}
+
+func TestRelayOutbox_HandlerNotFound(t *testing.T) {
+	defer clear()
+	s := NewMemoryOutboxStore()
+	if err := Stage(context.Background(), s, DummyEvent1{}); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	if _, err := RelayOutbox(context.Background(), s, 10); !errors.Is(err, ErrHandlerNotFound) {
+		t.Errorf("want err %v, got %v", ErrHandlerNotFound, err)
+	}
+	if s.Len() != 1 {
+		t.Errorf("want event kept in outbox, got %d", s.Len())
+	}
+}
+
+func TestRelayOutbox_MaxAttempts(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T, m *Mob) OutboxStore
+	}{
+		{
+			name: "memory",
+			store: func(*testing.T, *Mob) OutboxStore {
+				return NewMemoryOutboxStore()
+			},
+		},
+		{
+			name: "sql",
+			store: func(t *testing.T, m *Mob) OutboxStore {
+				db, _ := openOutboxDB(t)
+				return NewSQLOutboxStore(m, db, "outbox", PlaceholderQuestion)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			q := NewMemoryDeadLetterQueue()
+			SetDeadLetterQueueTo(m, q)
+			s := tt.store(t, m)
+			hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+			if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+				t.Fatalf("register handler: %v", err)
+			}
+			if err := StageTo(context.Background(), m, s, DummyEvent1{Int: 1}); err != nil {
+				t.Fatalf("stage: %v", err)
+			}
+			for i := 1; i <= DefaultOutboxMaxAttempts; i++ {
+				if _, err := RelayOutboxTo(context.Background(), m, s, 10); err == nil {
+					t.Fatal("want err, got nil")
+				}
+				pending, err := s.Pending(context.Background(), 10)
+				if err != nil {
+					t.Fatalf("pending: %v", err)
+				}
+				if i < DefaultOutboxMaxAttempts && (len(pending) != 1 || pending[0].Attempts != i || q.Len() != 0) {
+					t.Fatalf("want event kept with %d attempts and not dead-lettered, got %v and %d dead letters", i, pending, q.Len())
+				}
+				if i == DefaultOutboxMaxAttempts && len(pending) != 0 {
+					t.Fatalf("want event removed after its last attempt, got %v", pending)
+				}
+			}
+			if hn.Calls() != DefaultOutboxMaxAttempts {
+				t.Errorf("want handler called exactly %d, got %d", DefaultOutboxMaxAttempts, hn.Calls())
+			}
+			if q.Len() != 1 {
+				t.Errorf("want event dead-lettered once, got %d dead letters", q.Len())
+			}
+		})
+	}
+}
+
+func TestSQLOutboxStore_Undecodable(t *testing.T) {
+	m := New()
+	db, odb := openOutboxDB(t)
+	s := NewSQLOutboxStore(m, db, "outbox", PlaceholderQuestion)
+	odb.rows["1"] = outboxRow{id: "1", eventType: "unknown", payload: []byte("{}"), stagedAt: 1, metadata: "{}"}
+	odb.rows["2"] = outboxRow{id: "2", eventType: "github.com/erni27/mob.DummyEvent1", payload: []byte("{"), stagedAt: 2, metadata: "{}"}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := StageTo(context.Background(), m, s, DummyEvent1{Int: 997}); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	pending, err := s.Pending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("want undecodable entries skipped, got %v", err)
+	}
+	if len(pending) != 3 || pending[0].Event != nil || pending[1].Event != nil || pending[2].Event != (DummyEvent1{Int: 997}) {
+		t.Fatalf("want undecodable entries without events, got %v", pending)
+	}
+	n, err := RelayOutboxTo(context.Background(), m, s, 10)
+	if !errors.Is(err, ErrUnmarshal) {
+		t.Errorf("want err %v, got %v", ErrUnmarshal, err)
+	}
+	if n != 1 {
+		t.Errorf("want decodable event relayed, got %d", n)
+	}
+}
+
+func TestSQLOutboxStore_Tx(t *testing.T) {
+	m := New()
+	if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	db, odb := openOutboxDB(t)
+	s := NewSQLOutboxStore(m, db, "outbox", PlaceholderDollar)
+	stage := func(commit bool) {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		if err := StageTo(context.Background(), m, s.Tx(tx), DummyEvent1{String: "string", Int: 997}); err != nil {
+			t.Fatalf("stage: %v", err)
+		}
+		if commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+		}
+		if err != nil {
+			t.Fatalf("end tx: %v", err)
+		}
+	}
+	stage(false)
+	stage(true)
+	pending, err := s.Pending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("want only committed event pending, got %v", pending)
+	}
+	if ev := pending[0].Event; ev != (DummyEvent1{String: "string", Int: 997}) {
+		t.Errorf("want event decoded, got %v", ev)
+	}
+	if et := pending[0].EventType; et != "github.com/erni27/mob.DummyEvent1" {
+		t.Errorf("want event type github.com/erni27/mob.DummyEvent1, got %s", et)
+	}
+	if err := s.Remove(context.Background(), pending[0].ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	want := "INSERT INTO outbox (id, event_type, payload, staged_at, metadata, attempts) VALUES ($1, $2, $3, $4, $5, $6)"
+	if q := odb.queries[0]; q != want {
+		t.Errorf("want query %q, got %q", want, q)
+	}
+	want = "DELETE FROM outbox WHERE id IN ($1)"
+	if q := odb.queries[len(odb.queries)-1]; q != want {
+		t.Errorf("want query %q, got %q", want, q)
+	}
+}
+
+func TestStartOutboxRelay(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	var calls int
+	var hf EventHandlerFunc[DummyEvent1] = func(context.Context, DummyEvent1) error {
+		calls++
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	s := NewMemoryOutboxStore()
+	r, err := StartOutboxRelayTo(context.Background(), m, OutboxRelay{Store: s, Interval: time.Second, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("start relay: %v", err)
+	}
+	if err := StageTo(context.Background(), m, s, DummyEvent1{}, DummyEvent1{}, DummyEvent1{}); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	c.Advance(time.Second)
+	if calls != 3 {
+		t.Errorf("want all batches relayed, got %d events", calls)
+	}
+	if err := StageTo(context.Background(), m, s, DummyEvent1{}); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	r.Stop()
+	c.Advance(time.Second)
+	if calls != 3 {
+		t.Errorf("want no relay after stop, got %d events", calls)
+	}
+	m.Close()
+	if _, err := StartOutboxRelayTo(context.Background(), m, OutboxRelay{Store: s, Interval: time.Second}); !errors.Is(err, ErrClosed) {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestStartOutboxRelay_InvalidOption(t *testing.T) {
+	defer clear()
+	for _, or := range []OutboxRelay{
+		{Store: NewMemoryOutboxStore()},
+		{Store: NewMemoryOutboxStore(), Interval: time.Second, MaxAttempts: -1},
+	} {
+		if _, err := StartOutboxRelay(context.Background(), or); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+		}
+	}
+}
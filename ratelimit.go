@@ -0,0 +1,133 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A LimitMode determines the behavior of a rate limited handler when its limit is exceeded.
+type LimitMode int
+
+const (
+	// LimitWait blocks an invocation until the limit allows it or the context is done.
+	LimitWait LimitMode = iota
+	// LimitReject rejects an invocation immediately with ErrRateLimited.
+	LimitReject
+)
+
+// A rateLimit is a token bucket configuration.
+type rateLimit struct {
+	r     float64
+	burst int
+	mode  LimitMode
+}
+
+func (rl rateLimit) validate() error {
+	if rl.r <= 0 || rl.burst < 1 {
+		return fmt.Errorf("%w: rate limit requires positive rate and burst", ErrInvalidOption)
+	}
+	if rl.mode != LimitWait && rl.mode != LimitReject {
+		return fmt.Errorf("%w: unknown limit mode %d", ErrInvalidOption, rl.mode)
+	}
+	return nil
+}
+
+// A limiter is a token bucket rate limiter.
+type limiter struct {
+	mu     sync.Mutex
+	r      float64
+	burst  float64
+	mode   LimitMode
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rl rateLimit) *limiter {
+	return &limiter{r: rl.r, burst: float64(rl.burst), mode: rl.mode, tokens: float64(rl.burst), last: time.Now()}
+}
+
+// wait takes a token from the bucket. If there is no token available, it either
+// waits for one or returns ErrRateLimited, depending on the limiter's mode.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.r
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if l.mode == LimitReject {
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	// Reserve a token in advance, so concurrent waiters are queued.
+	d := time.Duration((1 - l.tokens) / l.r * float64(time.Second))
+	l.tokens--
+	l.mu.Unlock()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// A rateLimitedRequestHandler invokes the embedded handler only if the limiter allows it.
+type rateLimitedRequestHandler[T any, U any] struct {
+	embedded RequestHandler[T, U]
+	l        *limiter
+}
+
+func (h *rateLimitedRequestHandler[T, U]) Handle(ctx context.Context, req T) (U, error) {
+	if err := h.l.wait(ctx); err != nil {
+		var res U
+		return res, err
+	}
+	return h.embedded.Handle(ctx, req)
+}
+
+// A rateLimitedEventHandler invokes the embedded handler only if the limiter allows it.
+type rateLimitedEventHandler[T any] struct {
+	embedded EventHandler[T]
+	l        *limiter
+}
+
+func (h *rateLimitedEventHandler[T]) Handle(ctx context.Context, event T) error {
+	if err := h.l.wait(ctx); err != nil {
+		return err
+	}
+	return h.embedded.Handle(ctx, event)
+}
+
+// SetRateLimitTo limits the rate of requests and events dispatched by the given Mob instance.
+// The limit is a token bucket allowing r dispatches per second with bursts of at most burst dispatches.
+// Each Send and each Notify call takes a single token. The mode determines whether an exceeding
+// dispatch waits for a token or fails with ErrRateLimited.
+func SetRateLimitTo(m *Mob, r float64, burst int, mode LimitMode) error {
+	rl := rateLimit{r: r, burst: burst, mode: mode}
+	if err := rl.validate(); err != nil {
+		return err
+	}
+	m.limiter = newLimiter(rl)
+	return nil
+}
+
+// SetRateLimit limits the rate of requests and events dispatched by the global Mob instance.
+// The limit is a token bucket allowing r dispatches per second with bursts of at most burst dispatches.
+// Each Send and each Notify call takes a single token. The mode determines whether an exceeding
+// dispatch waits for a token or fails with ErrRateLimited.
+func SetRateLimit(r float64, burst int, mode LimitMode) error {
+	return SetRateLimitTo(m, r, burst, mode)
+}
@@ -0,0 +1,139 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSend_RateLimitReject(t *testing.T) {
+	defer clear()
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithName("DummyRequestHandler1"), WithRateLimit(0.001, 2, LimitReject)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want err %v, got %v", ErrRateLimited, err)
+	}
+}
+
+func TestSend_RateLimitWait(t *testing.T) {
+	defer clear()
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithRateLimit(50, 1, LimitWait)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("want sends to be delayed by the limit, took %v", elapsed)
+	}
+}
+
+func TestSend_RateLimitWaitContextCanceled(t *testing.T) {
+	defer clear()
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithRateLimit(0.001, 1, LimitWait)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := Send[DummyRequest1, DummyResponse1](ctx, DummyRequest1{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want err %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestNotify_RateLimitReject(t *testing.T) {
+	defer clear()
+	limited := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	unlimited := &DummyEventHandler2{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandler[DummyEvent1](limited, WithName("Limited"), WithRateLimit(0.001, 1, LimitReject)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](unlimited); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want err %v, got %v", ErrRateLimited, err)
+	}
+	if limited.Calls() != 1 {
+		t.Errorf("want limited handler called exactly 1, got %d", limited.Calls())
+	}
+	if unlimited.Calls() != 2 {
+		t.Errorf("want unlimited handler called exactly 2, got %d", unlimited.Calls())
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	defer clear()
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := SetRateLimit(0.001, 2, LimitReject); err != nil {
+		t.Fatalf("set rate limit: %v", err)
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if _, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want err %v, got %v", ErrRateLimited, err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want err %v, got %v", ErrRateLimited, err)
+	}
+}
+
+func TestWithRateLimit_InvalidOption(t *testing.T) {
+	defer clear()
+	tests := []struct {
+		name  string
+		r     float64
+		burst int
+		mode  LimitMode
+	}{
+		{name: "zero rate", r: 0, burst: 1, mode: LimitWait},
+		{name: "zero burst", r: 1, burst: 0, mode: LimitWait},
+		{name: "unknown mode", r: 1, burst: 1, mode: LimitMode(997)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithRateLimit(tt.r, tt.burst, tt.mode)); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+			if err := SetRateLimit(tt.r, tt.burst, tt.mode); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+		})
+	}
+}
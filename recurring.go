@@ -0,0 +1,131 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Recurrence configures a recurring notification.
+type Recurrence struct {
+	// Schedule determines activation times. Required.
+	Schedule Schedule
+	// Jitter is a maximum random delay added to each activation time. Zero means no jitter.
+	Jitter time.Duration
+	// AllowOverlap allows an activation while handlers notified by the previous one are still running.
+	// By default, such activations are skipped.
+	AllowOverlap bool
+	// OnError is called with an error returned by Notify. If nil, the error is passed to the Mob's error handler.
+	OnError func(err error)
+}
+
+// A Recurring is a handle of a recurring notification.
+type Recurring struct {
+	// skipped is accessed atomically, kept first for 64-bit alignment.
+	skipped uint64
+	m       *Mob
+	rec     Recurrence
+	ctx     context.Context
+	notify  func(t time.Time) error
+	running int32
+	// mu guards timer and stopped.
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// Stop stops the recurring notification. Handlers already running are not affected. Stop is idempotent.
+func (r *Recurring) Stop() {
+	r.stop()
+	stopBackground(r.m, r)
+}
+
+// Skipped returns the number of activations skipped because of overlapping.
+func (r *Recurring) Skipped() uint64 {
+	return atomic.LoadUint64(&r.skipped)
+}
+
+func (r *Recurring) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// schedule sets the timer for the first activation after a given time.
+// It must be called with r.mu held.
+func (r *Recurring) schedule(after time.Time) {
+	next := r.rec.Schedule.Next(after)
+	// A time not after the previous one would fire the timer over and over.
+	if next.IsZero() || !next.After(after) {
+		r.stopped = true
+		return
+	}
+	d := next.Sub(r.m.clock.Now())
+	if r.rec.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(r.rec.Jitter)))
+	}
+	r.timer = r.m.clock.AfterFunc(d, func() { r.fire(next) })
+}
+
+func (r *Recurring) fire(t time.Time) {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	r.schedule(t)
+	r.mu.Unlock()
+	if !r.rec.AllowOverlap {
+		if !atomic.CompareAndSwapInt32(&r.running, 0, 1) {
+			atomic.AddUint64(&r.skipped, 1)
+			return
+		}
+		defer atomic.StoreInt32(&r.running, 0)
+	}
+	if err := r.notify(t); err != nil {
+		if r.rec.OnError != nil {
+			r.rec.OnError(err)
+			return
+		}
+		handleError(r.ctx, r.m, err)
+	}
+}
+
+func (sc *scheduler[T]) NotifyRecurring(ctx context.Context, rec Recurrence, event func(t time.Time) T) (*Recurring, error) {
+	if rec.Schedule == nil || event == nil || rec.Jitter < 0 {
+		return nil, fmt.Errorf("%w: invalid recurrence", ErrInvalidOption)
+	}
+	if iv, ok := rec.Schedule.(interval); ok && iv <= 0 {
+		return nil, fmt.Errorf("%w: interval %v is not positive", ErrInvalidOption, time.Duration(iv))
+	}
+	ctx = detach(ctx)
+	nf := NewEventNotifier[T](sc.m)
+	r := &Recurring{
+		m:   sc.m,
+		rec: rec,
+		ctx: ctx,
+		notify: func(t time.Time) error {
+			return nf.Notify(ctx, event(t))
+		},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := startBackground(sc.m, r); err != nil {
+		return nil, err
+	}
+	r.schedule(sc.m.clock.Now())
+	return r, nil
+}
+
+// NotifyRecurring periodically notifies events to the global Mob instance according to a given recurrence.
+// An event is created by calling a given function with the activation time. Events are notified with a context
+// carrying the values of a given context, but never canceled.
+func NotifyRecurring[T any](ctx context.Context, rec Recurrence, event func(t time.Time) T) (*Recurring, error) {
+	return NewEventScheduler[T](m).NotifyRecurring(ctx, rec, event)
+}
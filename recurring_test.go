@@ -0,0 +1,181 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type DummyTick struct {
+	At time.Time
+}
+
+func TestNotifyRecurring(t *testing.T) {
+	m := New()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+	SetClockTo(m, c)
+	var got []time.Time
+	var ehf EventHandlerFunc[DummyTick] = func(_ context.Context, tick DummyTick) error {
+		got = append(got, tick.At)
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyTick](m, ehf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	r, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: Every(5 * time.Minute)}, func(t time.Time) DummyTick {
+		return DummyTick{At: t}
+	})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	c.Advance(16 * time.Minute)
+	r.Stop()
+	c.Advance(time.Hour)
+	want := []time.Time{start.Add(5 * time.Minute), start.Add(10 * time.Minute), start.Add(15 * time.Minute)}
+	if len(got) != len(want) {
+		t.Fatalf("want ticks %v, got %v", want, got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("want ticks %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNotifyRecurring_Overlap(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var calls int
+	var ehf EventHandlerFunc[DummyTick] = func(context.Context, DummyTick) error {
+		calls++
+		if calls == 1 {
+			close(entered)
+			<-release
+		}
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyTick](m, ehf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	r, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: Every(time.Minute)}, func(t time.Time) DummyTick {
+		return DummyTick{At: t}
+	})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	defer r.Stop()
+	done := make(chan struct{})
+	go func() {
+		c.Advance(time.Minute)
+		close(done)
+	}()
+	<-entered
+	// The first activation is still running, so the second one is skipped.
+	c.Advance(time.Minute)
+	close(release)
+	<-done
+	if calls != 1 {
+		t.Errorf("want handler called exactly 1, got %d", calls)
+	}
+	if skipped := r.Skipped(); skipped != 1 {
+		t.Errorf("want 1 skipped activation, got %d", skipped)
+	}
+	c.Advance(time.Minute)
+	if calls != 2 {
+		t.Errorf("want handler called exactly 2, got %d", calls)
+	}
+}
+
+func TestNotifyRecurring_OnError(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	var errs []error
+	r, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{
+		Schedule: Every(time.Minute),
+		OnError:  func(err error) { errs = append(errs, err) },
+	}, func(t time.Time) DummyTick {
+		return DummyTick{At: t}
+	})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	c.Advance(2 * time.Minute)
+	m.Close()
+	c.Advance(time.Hour)
+	if len(errs) != 2 || !errors.Is(errs[0], ErrHandlerNotFound) {
+		t.Errorf("want 2 errors %v, got %v", ErrHandlerNotFound, errs)
+	}
+	if n := c.Timers(); n != 0 {
+		t.Errorf("want timers stopped, got %d pending", n)
+	}
+	// Stop after Close must be safe.
+	r.Stop()
+}
+
+func TestNotifyRecurring_Jitter(t *testing.T) {
+	m := New()
+	start := time.Now()
+	c := NewManualClock(start)
+	SetClockTo(m, c)
+	r, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: Every(time.Minute), Jitter: 10 * time.Second}, func(t time.Time) DummyTick {
+		return DummyTick{At: t}
+	})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	defer r.Stop()
+	c.Advance(59 * time.Second)
+	if n := c.Timers(); n != 1 {
+		t.Fatalf("want activation not fired before schedule, got %d timers", n)
+	}
+}
+
+func TestNotifyRecurring_Invalid(t *testing.T) {
+	m := New()
+	if _, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{}, func(t time.Time) DummyTick { return DummyTick{} }); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: Every(d)}, func(t time.Time) DummyTick { return DummyTick{} }); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("want err %v for interval %v, got %v", ErrInvalidOption, d, err)
+		}
+	}
+	m.Close()
+	if _, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: Every(time.Minute)}, func(t time.Time) DummyTick { return DummyTick{} }); err != ErrClosed {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+}
+
+// stuckSchedule returns the given time as the next activation time.
+type stuckSchedule struct{}
+
+func (stuckSchedule) Next(t time.Time) time.Time { return t }
+
+func TestNotifyRecurring_StuckSchedule(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetClockTo(m, c)
+	var calls int
+	var hf EventHandlerFunc[DummyTick] = func(context.Context, DummyTick) error {
+		calls++
+		return nil
+	}
+	if err := RegisterEventHandlerTo[DummyTick](m, hf); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	r, err := NewEventScheduler[DummyTick](m).NotifyRecurring(context.Background(), Recurrence{Schedule: stuckSchedule{}}, func(t time.Time) DummyTick { return DummyTick{At: t} })
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	defer r.Stop()
+	c.Advance(time.Minute)
+	if calls != 0 || c.Timers() != 0 {
+		t.Errorf("want recurrence stopped, got %d calls and %d timers", calls, c.Timers())
+	}
+}
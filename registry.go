@@ -0,0 +1,122 @@
+package mob
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterTypeTo registers a type T under a given name in the given Mob instance's type registry.
+// Types of requests, responses and events are registered under names qualified with their package paths
+// as soon as their handlers are registered. RegisterTypeTo overrides such a name, so it remains stable
+// when a type is moved or renamed. Messages encoded under the previous name can still be decoded.
+//
+// If the name is already taken by another type, ErrDuplicateType is returned.
+func RegisterTypeTo[T any](m *Mob, name string) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if name == "" {
+		return fmt.Errorf("%w: empty type name", ErrInvalidOption)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rt, ok := m.types[name]; ok && rt != t {
+		return fmt.Errorf("%w: %s is taken by %s", ErrDuplicateType, name, typeName(rt))
+	}
+	m.types[name] = t
+	m.names[t] = name
+	return nil
+}
+
+// RegisterType registers a type T under a given name in the global Mob instance's type registry.
+//
+// If the name is already taken by another type, ErrDuplicateType is returned.
+func RegisterType[T any](name string) error {
+	return RegisterTypeTo[T](m, name)
+}
+
+// TypeNameTo returns a name a given message's type is registered under in the given Mob instance.
+// If the type is not registered, its name qualified with its package path is returned.
+func TypeNameTo(m *Mob, msg interface{}) string {
+	return nameOf(m, reflect.TypeOf(msg))
+}
+
+// TypeName returns a name a given message's type is registered under in the global Mob instance.
+// If the type is not registered, its name qualified with its package path is returned.
+func TypeName(msg interface{}) string {
+	return TypeNameTo(m, msg)
+}
+
+// EncodeTo encodes a given message by a given Codec. Returns the name of the message's type
+// in the given Mob instance's type registry and the encoded message.
+func EncodeTo(m *Mob, c Codec, msg interface{}) (string, []byte, error) {
+	data, err := c.Marshal(msg)
+	if err != nil {
+		return "", nil, err
+	}
+	return TypeNameTo(m, msg), data, nil
+}
+
+// Encode encodes a given message by a given Codec. Returns the name of the message's type
+// in the global Mob instance's type registry and the encoded message.
+func Encode(c Codec, msg interface{}) (string, []byte, error) {
+	return EncodeTo(m, c, msg)
+}
+
+// DecodeTo decodes data by a given Codec into a new value of a type registered under a given name
+// in the given Mob instance's type registry.
+//
+// If no type is registered under the name, ErrUnknownType is returned.
+func DecodeTo(m *Mob, c Codec, name string, data []byte) (interface{}, error) {
+	t, ok := typeOf(m, name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, name)
+	}
+	v := reflect.New(t)
+	if err := c.Unmarshal(data, v.Interface()); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrUnmarshal, name, err)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// Decode decodes data by a given Codec into a new value of a type registered under a given name
+// in the global Mob instance's type registry.
+//
+// If no type is registered under the name, ErrUnknownType is returned.
+func Decode(c Codec, name string, data []byte) (interface{}, error) {
+	return DecodeTo(m, c, name, data)
+}
+
+// registerType registers a given type under its qualified name, unless the type is already registered.
+// It must be called with m.mu held.
+func registerType(m *Mob, t reflect.Type) {
+	if t == nil {
+		return
+	}
+	if _, ok := m.names[t]; ok {
+		return
+	}
+	name := typeName(t)
+	if _, ok := m.types[name]; ok {
+		return
+	}
+	m.types[name] = t
+	m.names[t] = name
+}
+
+// nameOf returns a name a given type is registered under.
+func nameOf(m *Mob, t reflect.Type) string {
+	m.mu.RLock()
+	name, ok := m.names[t]
+	m.mu.RUnlock()
+	if ok {
+		return name
+	}
+	return typeName(t)
+}
+
+// typeOf returns a type registered under a given name.
+func typeOf(m *Mob, name string) (reflect.Type, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.types[name]
+	return t, ok
+}
@@ -0,0 +1,114 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(m *Mob) error
+		msg      interface{}
+		wantName string
+	}{
+		{
+			name: "request",
+			register: func(m *Mob) error {
+				return RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, DummyRequestHandler1{})
+			},
+			msg:      DummyRequest1{String: "string"},
+			wantName: "github.com/erni27/mob.DummyRequest1",
+		},
+		{
+			name: "response",
+			register: func(m *Mob) error {
+				return RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, DummyRequestHandler1{})
+			},
+			msg:      DummyResponse1{String: "string", Int: 997},
+			wantName: "github.com/erni27/mob.DummyResponse1",
+		},
+		{
+			name: "event",
+			register: func(m *Mob) error {
+				return RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{})
+			},
+			msg:      DummyEvent1{String: "string", Int: 997},
+			wantName: "github.com/erni27/mob.DummyEvent1",
+		},
+		{
+			name: "pointer event",
+			register: func(m *Mob) error {
+				return RegisterEventHandlerTo[*DummyEvent1](m, EventHandlerFunc[*DummyEvent1](func(context.Context, *DummyEvent1) error { return nil }))
+			},
+			msg:      &DummyEvent1{String: "string", Int: 997},
+			wantName: "*github.com/erni27/mob.DummyEvent1",
+		},
+		{
+			name: "overridden name",
+			register: func(m *Mob) error {
+				if err := RegisterEventHandlerTo[DummyEvent1](m, &DummyEventHandler4{}); err != nil {
+					return err
+				}
+				return RegisterTypeTo[DummyEvent1](m, "dummy.event.v1")
+			},
+			msg:      DummyEvent1{String: "string", Int: 997},
+			wantName: "dummy.event.v1",
+		},
+	}
+	for _, tt := range tests {
+		for _, c := range []Codec{JSONCodec{}, GobCodec{}} {
+			t.Run(tt.name+"/"+reflect.TypeOf(c).Name(), func(t *testing.T) {
+				m := New()
+				if err := tt.register(m); err != nil {
+					t.Fatalf("register: %v", err)
+				}
+				name, data, err := EncodeTo(m, c, tt.msg)
+				if err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+				if name != tt.wantName {
+					t.Errorf("want name %s, got %s", tt.wantName, name)
+				}
+				got, err := DecodeTo(m, c, name, data)
+				if err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.msg) {
+					t.Errorf("want %v, got %v", tt.msg, got)
+				}
+			})
+		}
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	defer clear()
+	if err := RegisterType[DummyEvent1]("dummy"); err != nil {
+		t.Fatalf("register type: %v", err)
+	}
+	// Handler registration doesn't override the name.
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if name := TypeName(DummyEvent1{}); name != "dummy" {
+		t.Errorf("want name dummy, got %s", name)
+	}
+	if err := RegisterType[DummyRequest1]("dummy"); !errors.Is(err, ErrDuplicateType) {
+		t.Errorf("want err %v, got %v", ErrDuplicateType, err)
+	}
+	if err := RegisterType[DummyRequest1](""); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	if _, err := Decode(JSONCodec{}, "unknown", []byte("{}")); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("want err %v, got %v", ErrUnknownType, err)
+	}
+	if _, err := Decode(JSONCodec{}, "dummy", []byte("malformed")); !errors.Is(err, ErrUnmarshal) {
+		t.Errorf("want err %v, got %v", ErrUnmarshal, err)
+	}
+	if name, _, err := Encode(JSONCodec{}, DummyRequest2{}); err != nil || name != "github.com/erni27/mob.DummyRequest2" {
+		t.Errorf("want unregistered type encoded under its qualified name, got %s, %v", name, err)
+	}
+}
@@ -0,0 +1,199 @@
+package mob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A Remote configures a client of a remote Mob instance exposed with NewHTTPHandler.
+type Remote struct {
+	// URL is a base URL of the remote Mob instance's HTTP handler. Required.
+	URL string
+	// Client is an HTTP client sending requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Timeout limits a single attempt. Zero means no timeout.
+	Timeout time.Duration
+	// Retries is a number of retries of an attempt failed because of a transport error or because
+	// the remote Mob instance is unavailable, rate limited or timed out.
+	Retries int
+	// Backoff is a duration between attempts. It doubles after each retry.
+	Backoff time.Duration
+	// Header, if not nil, is called before each attempt to set headers of an HTTP request,
+	// e.g. to propagate values of a given context. Metadata of the context is propagated anyway.
+	Header func(ctx context.Context, h http.Header)
+}
+
+// A RemoteError is an error returned by a remote Mob instance.
+// It matches errors.Is with a mob's error it's caused by, e.g. ErrHandlerNotFound.
+type RemoteError struct {
+	// StatusCode is a status code of an HTTP response.
+	StatusCode int
+	// Code is a code of the error.
+	Code string
+	// Message is a message of the error.
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "mob: remote: " + e.Message
+}
+
+func (e *RemoteError) Is(target error) bool {
+	switch e.Code {
+	case httpCodeHandlerNotFound, httpCodeUnknownType:
+		return target == ErrHandlerNotFound
+	case httpCodeAmbiguous:
+		return target == ErrAmbiguousRequest
+	case httpCodeBadRequest:
+		return target == ErrUnmarshal
+	case httpCodeInvalidHandler:
+		return target == ErrInvalidHandler
+	case httpCodeRateLimited:
+		return target == ErrRateLimited
+	case httpCodeTimeout:
+		return target == ErrHandlerTimeout
+	case httpCodeClosed:
+		return target == ErrClosed
+	}
+	return false
+}
+
+// NewRemoteRequestSender returns a request sender which sends requests to a remote Mob instance.
+// Requests are named after types in the given Mob instance's type registry, so names of types registered
+// under custom names must match names registered in the remote Mob instance.
+//
+// Retried requests may be handled more than once.
+func NewRemoteRequestSender[T any, U any](m *Mob, r Remote) RequestSender[T, U] {
+	return &remoteSender[T, U]{c: newRemoteClient(m, r)}
+}
+
+type remoteSender[T any, U any] struct {
+	c *remoteClient
+}
+
+func (s *remoteSender[T, U]) Send(ctx context.Context, req T) (U, error) {
+	var res U
+	body, err := s.c.call(ctx, httpSendPrefix, req)
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return res, fmt.Errorf("%w: response: %v", ErrUnmarshal, err)
+	}
+	return res, nil
+}
+
+// NewRemoteEventNotifier returns an event notifier which notifies events to a remote Mob instance.
+// Events are named after types in the given Mob instance's type registry, so names of types registered
+// under custom names must match names registered in the remote Mob instance.
+//
+// Retried events may be handled more than once.
+func NewRemoteEventNotifier[T any](m *Mob, r Remote) EventNotifier[T] {
+	return &remoteNotifier[T]{c: newRemoteClient(m, r)}
+}
+
+type remoteNotifier[T any] struct {
+	c *remoteClient
+}
+
+func (nf *remoteNotifier[T]) Notify(ctx context.Context, event T) error {
+	_, err := nf.c.call(ctx, httpNotifyPrefix, event)
+	return err
+}
+
+// A remoteClient calls a remote Mob instance's HTTP handler.
+type remoteClient struct {
+	m *Mob
+	r Remote
+}
+
+func newRemoteClient(m *Mob, r Remote) *remoteClient {
+	r.URL = strings.TrimSuffix(r.URL, "/")
+	if r.Client == nil {
+		r.Client = http.DefaultClient
+	}
+	return &remoteClient{m: m, r: r}
+}
+
+// call posts a given message to a path with a given prefix and returns the response's body.
+func (c *remoteClient) call(ctx context.Context, prefix string, msg interface{}) ([]byte, error) {
+	name, body, err := EncodeTo(c.m, JSONCodec{}, msg)
+	if err != nil {
+		return nil, err
+	}
+	url := c.r.URL + prefix + name
+	backoff := c.r.Backoff
+	for attempt := 0; ; attempt++ {
+		res, err := c.attempt(ctx, url, body)
+		if err == nil || attempt == c.r.Retries || !retryable(ctx, err) {
+			return res, err
+		}
+		if err := sleep(ctx, c.m.clock, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+func (c *remoteClient) attempt(ctx context.Context, url string, body []byte) ([]byte, error) {
+	if c.r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.r.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if md := MetadataFrom(ctx); md != nil {
+		req.Header.Set(httpMetadataHeader, md.encode())
+	}
+	if c.r.Header != nil {
+		c.r.Header(ctx, req.Header)
+	}
+	res, err := c.r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		rerr := &RemoteError{StatusCode: res.StatusCode}
+		var herr httpError
+		if json.Unmarshal(data, &herr) == nil && herr.Code != "" {
+			rerr.Code, rerr.Message = herr.Code, herr.Message
+		} else {
+			rerr.Message = http.StatusText(res.StatusCode)
+		}
+		return nil, rerr
+	}
+	return data, nil
+}
+
+// retryable reports whether an attempt failed with a given error can be retried.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var rerr *RemoteError
+	if !errors.As(err, &rerr) {
+		// A transport error or an attempt timeout.
+		return true
+	}
+	switch rerr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
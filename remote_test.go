@@ -0,0 +1,97 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemote(t *testing.T) {
+	srv := httptest.NewServer(NewHTTPHandler(newTestHTTPMob(t), HTTPOptions{}))
+	defer srv.Close()
+	// The client side only needs the types registered.
+	m := New()
+	r := Remote{URL: srv.URL + "/", Client: srv.Client()}
+
+	s := NewRemoteRequestSender[DummyRequest1, DummyResponse1](m, r)
+	res, err := s.Send(context.Background(), DummyRequest1{String: "string"})
+	if err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if res.String != "string" || res.Int != 997 {
+		t.Errorf("want response decoded, got %v", res)
+	}
+	if _, err := s.Send(context.Background(), DummyRequest1{String: "limit"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want err %v, got %v", ErrRateLimited, err)
+	}
+	var rerr *RemoteError
+	if _, err := s.Send(context.Background(), DummyRequest1{String: "fail"}); !errors.As(err, &rerr) || rerr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("want remote error, got %v", err)
+	}
+
+	nf := NewRemoteEventNotifier[DummyEvent1](m, r)
+	if err := nf.Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+	if err := NewRemoteEventNotifier[DummyRequest2](m, r).Notify(context.Background(), DummyRequest2{}); !errors.Is(err, ErrHandlerNotFound) {
+		t.Errorf("want err %v, got %v", ErrHandlerNotFound, err)
+	}
+}
+
+func TestRemote_Retry(t *testing.T) {
+	var calls int32
+	h := NewHTTPHandler(newTestHTTPMob(t), HTTPOptions{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	r := Remote{URL: srv.URL, Client: srv.Client(), Retries: 2, Backoff: time.Millisecond}
+	if err := NewRemoteEventNotifier[DummyEvent1](New(), r).Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("want 3 attempts, got %d", calls)
+	}
+	// Handler errors aren't retried.
+	atomic.StoreInt32(&calls, 2)
+	if err := NewRemoteEventNotifier[DummyEvent1](New(), r).Notify(context.Background(), DummyEvent1{String: "fail"}); err == nil {
+		t.Error("want err, got nil")
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("want 1 attempt, got %d", calls-2)
+	}
+}
+
+func TestRemote_TimeoutAndHeader(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	type tenantKey struct{}
+	r := Remote{
+		URL:     srv.URL,
+		Client:  srv.Client(),
+		Timeout: 10 * time.Millisecond,
+		Header: func(ctx context.Context, h http.Header) {
+			h.Set("X-Tenant", ctx.Value(tenantKey{}).(string))
+		},
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if err := NewRemoteEventNotifier[DummyEvent1](New(), r).Notify(ctx, DummyEvent1{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want err %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,106 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// A retry configures retries of a failed handler.
+type retry struct {
+	attempts int
+	backoff  time.Duration
+}
+
+func (r retry) validate() error {
+	if r.attempts < 1 || r.backoff < 0 {
+		return fmt.Errorf("%w: retry requires positive attempts and non-negative backoff", ErrInvalidOption)
+	}
+	return nil
+}
+
+// do calls f until it succeeds, the attempts are exhausted or the context is done.
+// The backoff doubles after each attempt.
+func (r retry) do(ctx context.Context, c Clock, f func() error) error {
+	backoff := r.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = f(); err == nil {
+			return nil
+		}
+		if attempt == r.attempts {
+			return &attemptsError{err: err, attempts: attempt}
+		}
+		if err := sleep(ctx, c, backoff); err != nil {
+			return &attemptsError{err: err, attempts: attempt}
+		}
+		backoff *= 2
+	}
+}
+
+// An attemptsError is an error of a handler returned after a number of attempts.
+type attemptsError struct {
+	err      error
+	attempts int
+}
+
+func (e *attemptsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *attemptsError) Unwrap() error {
+	return e.err
+}
+
+// sleep waits for a given duration measured by a given clock or until the context is done.
+func sleep(ctx context.Context, c Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	done := make(chan struct{})
+	t := c.AfterFunc(d, func() { close(done) })
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.Stop()
+		return ctx.Err()
+	}
+}
+
+// A retryingRequestHandler retries the embedded handler until it succeeds.
+type retryingRequestHandler[T any, U any] struct {
+	embedded RequestHandler[T, U]
+	r        retry
+	// m is the handler's Mob instance, its clock is read on each call so it can be set after the registration.
+	m *Mob
+}
+
+func (h *retryingRequestHandler[T, U]) Handle(ctx context.Context, req T) (U, error) {
+	var res U
+	err := h.r.do(ctx, h.m.clock, func() error {
+		// Events raised by a failed attempt are discarded.
+		actx, commit := nestUnitOfWork(ctx)
+		var err error
+		res, err = h.embedded.Handle(actx, req)
+		if err == nil {
+			commit()
+		}
+		return err
+	})
+	return res, err
+}
+
+// A retryingEventHandler retries the embedded handler until it succeeds.
+type retryingEventHandler[T any] struct {
+	embedded EventHandler[T]
+	r        retry
+	// m is the handler's Mob instance, read like in retryingRequestHandler.
+	m *Mob
+}
+
+func (h *retryingEventHandler[T]) Handle(ctx context.Context, event T) error {
+	return h.r.do(ctx, h.m.clock, func() error {
+		return h.embedded.Handle(ctx, event)
+	})
+}
@@ -0,0 +1,115 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSend_Retry(t *testing.T) {
+	defer clear()
+	errDummy := errors.New("dummy")
+	var calls int
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(context.Context, DummyRequest1) (DummyResponse1, error) {
+		calls++
+		if calls < 3 {
+			return DummyResponse1{}, errDummy
+		}
+		return DummyResponse1{Int: calls}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithRetry(3, time.Millisecond)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	res, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{})
+	if err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	if res.Int != 3 {
+		t.Errorf("want response of the third attempt, got %v", res)
+	}
+}
+
+func TestNotify_Retry(t *testing.T) {
+	defer clear()
+	errDummy := errors.New("dummy")
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errDummy }}
+	if err := RegisterEventHandler[DummyEvent1](hn, WithName("Retried"), WithRetry(3, 0)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	err := Notify(context.Background(), DummyEvent1{})
+	if !errors.Is(err, errDummy) {
+		t.Errorf("want %v, got %v", errDummy, err)
+	}
+	if err.Error() != "Retried: dummy" {
+		t.Errorf("want named err, got %v", err)
+	}
+	if calls := hn.Calls(); calls != 3 {
+		t.Errorf("want handler called exactly 3, got %d", calls)
+	}
+}
+
+func TestNotify_RetryBackoff(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithRetry(3, time.Second)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{})
+	}()
+	// Backoffs are 1s and 2s.
+	for _, d := range []time.Duration{time.Second, 2 * time.Second} {
+		for c.Timers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		c.Advance(d - time.Nanosecond)
+		if c.Timers() != 1 {
+			t.Fatalf("want backoff of %v", d)
+		}
+		c.Advance(time.Nanosecond)
+	}
+	if err := <-done; err == nil {
+		t.Error("want err, got nil")
+	}
+	if calls := hn.Calls(); calls != 3 {
+		t.Errorf("want handler called exactly 3, got %d", calls)
+	}
+}
+
+func TestNotify_RetryClockSetAfterRegistration(t *testing.T) {
+	m := New()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn, WithRetry(2, time.Hour)); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	done := make(chan error)
+	go func() {
+		done <- NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{})
+	}()
+	for c.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(time.Hour)
+	if err := <-done; err == nil {
+		t.Error("want err, got nil")
+	}
+	if calls := hn.Calls(); calls != 2 {
+		t.Errorf("want handler called exactly 2, got %d", calls)
+	}
+}
+
+func TestWithRetry_InvalidOption(t *testing.T) {
+	defer clear()
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithRetry(0, 0)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](DummyRequestHandler1{}, WithRetry(1, -time.Second)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+}
@@ -0,0 +1,157 @@
+package mob
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduled notification states.
+const (
+	scheduledPending int32 = iota
+	scheduledFiring
+	scheduledCanceled
+)
+
+// A Scheduled is a handle of a scheduled notification.
+type Scheduled struct {
+	m     *Mob
+	state int32
+	// mu guards timer, which is set after the notification is tracked by its Mob instance.
+	mu    sync.Mutex
+	timer Timer
+	done  chan struct{}
+	err   error
+}
+
+// Cancel cancels the scheduled notification.
+// It returns true if the notification is canceled, false if it's already delivered or canceled.
+func (s *Scheduled) Cancel() bool {
+	return s.cancel(context.Canceled)
+}
+
+// Done returns a channel that's closed when the scheduled notification is delivered or canceled.
+func (s *Scheduled) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns an error of the scheduled notification once it's done.
+// It's an error returned by Notify if the notification is delivered, context.Canceled if the notification
+// is canceled and ErrClosed if the notification is canceled because the Mob instance is closed.
+// Before the notification is done, Err returns nil.
+func (s *Scheduled) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *Scheduled) stop() {
+	s.cancel(ErrClosed)
+}
+
+func (s *Scheduled) cancel(err error) bool {
+	if !atomic.CompareAndSwapInt32(&s.state, scheduledPending, scheduledCanceled) {
+		return false
+	}
+	s.mu.Lock()
+	s.timer.Stop()
+	s.mu.Unlock()
+	stopBackground(s.m, s)
+	s.err = err
+	close(s.done)
+	return true
+}
+
+// EventScheduler is the interface that wraps the mob's NotifyAt, NotifyAfter and NotifyRecurring methods.
+type EventScheduler[T any] interface {
+	// NotifyAt schedules a given event to be notified at a given time.
+	// The event is notified with a context carrying the values of a given context, but never canceled.
+	//
+	// If the scheduler's Mob instance is closed, ErrClosed is returned.
+	NotifyAt(ctx context.Context, t time.Time, event T) (*Scheduled, error)
+	// NotifyAfter schedules a given event to be notified after a given duration.
+	// The event is notified with a context carrying the values of a given context, but never canceled.
+	//
+	// If the scheduler's Mob instance is closed, ErrClosed is returned.
+	NotifyAfter(ctx context.Context, d time.Duration, event T) (*Scheduled, error)
+	// NotifyRecurring periodically notifies events according to a given recurrence.
+	// An event is created by calling a given function with the activation time.
+	// Events are notified with a context carrying the values of a given context, but never canceled.
+	//
+	// If the scheduler's Mob instance is closed, ErrClosed is returned.
+	NotifyRecurring(ctx context.Context, rec Recurrence, event func(t time.Time) T) (*Recurring, error)
+}
+
+// NewEventScheduler returns an event scheduler which uses a given Mob instance.
+func NewEventScheduler[T any](m *Mob) EventScheduler[T] {
+	return &scheduler[T]{m: m}
+}
+
+// A scheduler is a facilitator for a given event type.
+type scheduler[T any] struct {
+	m *Mob
+}
+
+func (sc *scheduler[T]) NotifyAt(ctx context.Context, t time.Time, event T) (*Scheduled, error) {
+	return sc.NotifyAfter(ctx, t.Sub(sc.m.clock.Now()), event)
+}
+
+func (sc *scheduler[T]) NotifyAfter(ctx context.Context, d time.Duration, event T) (*Scheduled, error) {
+	s := &Scheduled{m: sc.m, done: make(chan struct{})}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := startBackground(sc.m, s); err != nil {
+		return nil, err
+	}
+	ctx = detach(ctx)
+	s.timer = sc.m.clock.AfterFunc(d, func() {
+		if !atomic.CompareAndSwapInt32(&s.state, scheduledPending, scheduledFiring) {
+			return
+		}
+		stopBackground(sc.m, s)
+		s.err = NewEventNotifier[T](sc.m).Notify(ctx, event)
+		close(s.done)
+	})
+	return s, nil
+}
+
+// NotifyAt schedules a given event to be notified to the global Mob instance at a given time.
+// The event is notified with a context carrying the values of a given context, but never canceled.
+func NotifyAt[T any](ctx context.Context, t time.Time, event T) (*Scheduled, error) {
+	return NewEventScheduler[T](m).NotifyAt(ctx, t, event)
+}
+
+// NotifyAfter schedules a given event to be notified to the global Mob instance after a given duration.
+// The event is notified with a context carrying the values of a given context, but never canceled.
+func NotifyAfter[T any](ctx context.Context, d time.Duration, event T) (*Scheduled, error) {
+	return NewEventScheduler[T](m).NotifyAfter(ctx, d, event)
+}
+
+// A detachedContext carries the values of its parent, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,140 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNotifyAfter(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetClockTo(m, c)
+	type ctxKey struct{}
+	hn := &DummyEventHandler1{handleFunc: func(ctx context.Context, _ DummyEvent1) error {
+		if ctx.Value(ctxKey{}) != "value" {
+			t.Errorf("want context value propagated, got %v", ctx.Value(ctxKey{}))
+		}
+		if ctx.Err() != nil {
+			t.Errorf("want detached context, got %v", ctx.Err())
+		}
+		return nil
+	}}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	s, err := NewEventScheduler[DummyEvent1](m).NotifyAfter(ctx, time.Minute, DummyEvent1{})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	cancel()
+	c.Advance(59 * time.Second)
+	if calls := hn.Calls(); calls != 0 {
+		t.Fatalf("want handler not called before deadline, got %d", calls)
+	}
+	c.Advance(time.Second)
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("want scheduled notification done")
+	}
+	if calls := hn.Calls(); calls != 1 {
+		t.Errorf("want handler called exactly 1, got %d", calls)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+	if s.Cancel() {
+		t.Error("want delivered notification not canceled")
+	}
+}
+
+func TestNotifyAt_Error(t *testing.T) {
+	m := New()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(now)
+	SetClockTo(m, c)
+	s, err := NewEventScheduler[DummyEvent1](m).NotifyAt(context.Background(), now.Add(time.Hour), DummyEvent1{})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("want no err before delivery, got %v", err)
+	}
+	c.Advance(time.Hour)
+	if err := s.Err(); err != ErrHandlerNotFound {
+		t.Errorf("want err %v, got %v", ErrHandlerNotFound, err)
+	}
+}
+
+func TestScheduled_Cancel(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	s, err := NewEventScheduler[DummyEvent1](m).NotifyAfter(context.Background(), time.Minute, DummyEvent1{})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if !s.Cancel() {
+		t.Fatal("want pending notification canceled")
+	}
+	if s.Cancel() {
+		t.Error("want canceled notification not canceled again")
+	}
+	c.Advance(time.Hour)
+	if calls := hn.Calls(); calls != 0 {
+		t.Errorf("want handler not called, got %d", calls)
+	}
+	if err := s.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("want err %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestMob_Close(t *testing.T) {
+	m := New()
+	c := NewManualClock(time.Now())
+	SetClockTo(m, c)
+	sc := NewEventScheduler[DummyEvent1](m)
+	s, err := sc.NotifyAfter(context.Background(), time.Minute, DummyEvent1{})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	m.Close()
+	if err := s.Err(); err != ErrClosed {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+	if n := c.Timers(); n != 0 {
+		t.Errorf("want timers stopped, got %d pending", n)
+	}
+	if _, err := sc.NotifyAfter(context.Background(), time.Minute, DummyEvent1{}); err != ErrClosed {
+		t.Errorf("want err %v, got %v", ErrClosed, err)
+	}
+	// Close must be idempotent.
+	m.Close()
+}
+
+func TestNotifyAfter_SystemClock(t *testing.T) {
+	defer clear()
+	hn := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return nil }}
+	if err := RegisterEventHandler[DummyEvent1](hn); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	s, err := NotifyAfter(context.Background(), time.Millisecond, DummyEvent1{})
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("want scheduled notification delivered")
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+}
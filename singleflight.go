@@ -0,0 +1,88 @@
+package mob
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// A flight is an in-flight or completed singleflight handler execution.
+type flight[U any] struct {
+	done chan struct{}
+	res  U
+	err  error
+	// p is a value the handler panicked with, if panicked is set.
+	p        interface{}
+	panicked bool
+}
+
+// A flightKey identifies a flight by a request and values of the handler's metadata keys.
+type flightKey struct {
+	req interface{}
+	// md is encoded metadata of the handler's metadata keys.
+	md string
+}
+
+// A singleflightRequestHandler deduplicates concurrent executions of the embedded handler for equal requests.
+type singleflightRequestHandler[T any, U any] struct {
+	embedded RequestHandler[T, U]
+	// keys are metadata keys whose values must be equal for requests to be deduplicated.
+	keys    []string
+	mu      sync.Mutex
+	flights map[flightKey]*flight[U]
+}
+
+func newSingleflightRequestHandler[T any, U any](rhn RequestHandler[T, U], keys []string) *singleflightRequestHandler[T, U] {
+	return &singleflightRequestHandler[T, U]{embedded: rhn, keys: keys, flights: map[flightKey]*flight[U]{}}
+}
+
+func (h *singleflightRequestHandler[T, U]) Handle(ctx context.Context, req T) (U, error) {
+	// A request type can be an interface type whose dynamic type is not comparable.
+	if t := reflect.TypeOf(req); t != nil && !t.Comparable() {
+		return h.embedded.Handle(ctx, req)
+	}
+	key := flightKey{req: req}
+	if len(h.keys) > 0 {
+		md, scoped := MetadataFrom(ctx), Metadata{}
+		for _, k := range h.keys {
+			if v, ok := md[k]; ok {
+				scoped[k] = v
+			}
+		}
+		key.md = scoped.encode()
+	}
+	h.mu.Lock()
+	f, ok := h.flights[key]
+	if !ok {
+		f = &flight[U]{done: make(chan struct{})}
+		h.flights[key] = f
+		// The execution is shared, so it mustn't be canceled by the context of the caller starting it.
+		fctx := detach(ctx)
+		go func() {
+			defer close(f.done)
+			defer func() {
+				h.mu.Lock()
+				delete(h.flights, key)
+				h.mu.Unlock()
+			}()
+			// A panic is recovered here and propagated to the callers waiting for the flight.
+			defer func() {
+				if r := recover(); r != nil {
+					f.p, f.panicked = r, true
+				}
+			}()
+			f.res, f.err = h.embedded.Handle(fctx, req)
+		}()
+	}
+	h.mu.Unlock()
+	select {
+	case <-f.done:
+		if f.panicked {
+			panic(f.p)
+		}
+		return f.res, f.err
+	case <-ctx.Done():
+		var res U
+		return res, ctx.Err()
+	}
+}
@@ -0,0 +1,221 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSend_Singleflight(t *testing.T) {
+	defer clear()
+	errDummy := errors.New("dummy error")
+	var calls int32
+	release := make(chan struct{})
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, req DummyRequest1) (DummyResponse1, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return DummyResponse1{String: req.String}, errDummy
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithSingleflight()); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	const n = 10
+	var wg sync.WaitGroup
+	var started sync.WaitGroup
+	results := make([]DummyResponse1, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			results[i], errs[i] = Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{String: "dummy"})
+		}(i)
+	}
+	started.Wait()
+	// Give all senders a chance to join the in-flight execution.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("want handler called exactly 1, got %d", got)
+	}
+	for i := 0; i < n; i++ {
+		if !errors.Is(errs[i], errDummy) {
+			t.Errorf("want err %v, got %v", errDummy, errs[i])
+		}
+		if results[i].String != "dummy" {
+			t.Errorf("want shared response, got %v", results[i])
+		}
+	}
+}
+
+func TestSend_SingleflightDistinctRequests(t *testing.T) {
+	defer clear()
+	var calls int32
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, req DummyRequest1) (DummyResponse1, error) {
+		atomic.AddInt32(&calls, 1)
+		return DummyResponse1{String: req.String}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithSingleflight()); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	for _, s := range []string{"first", "second", "first"} {
+		res, err := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{String: s})
+		if err != nil {
+			t.Fatalf("unexpected send err: %v", err)
+		}
+		if res.String != s {
+			t.Errorf("want %s, got %s", s, res.String)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("want handler called exactly 3, got %d", got)
+	}
+}
+
+func TestSend_SingleflightMetadataKeys(t *testing.T) {
+	m := New()
+	var calls int32
+	release := make(chan struct{})
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return DummyResponse1{String: MetadataFrom(ctx)["tenant"]}, nil
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, hf, WithSingleflight("tenant")); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	tenants := []string{"a", "b", "a"}
+	results := make([]DummyResponse1, len(tenants))
+	var wg sync.WaitGroup
+	for i, tenant := range tenants {
+		wg.Add(1)
+		go func(i int, tenant string) {
+			defer wg.Done()
+			ctx := WithMetadata(context.Background(), "tenant", tenant)
+			results[i], _ = NewRequestSender[DummyRequest1, DummyResponse1](m).Send(ctx, DummyRequest1{String: "dummy"})
+		}(i, tenant)
+	}
+	// Give all senders a chance to join the in-flight executions.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("want handler called once per tenant, got %d calls", got)
+	}
+	for i, tenant := range tenants {
+		if results[i].String != tenant {
+			t.Errorf("want response of tenant %s, got %v", tenant, results[i])
+		}
+	}
+}
+
+func TestSend_SingleflightWaiterContextCanceled(t *testing.T) {
+	defer clear()
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{})
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(_ context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		close(entered)
+		<-release
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithSingleflight()); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	go func() {
+		_, _ = Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{})
+	}()
+	<-entered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Send[DummyRequest1, DummyResponse1](ctx, DummyRequest1{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("want err %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestSend_SingleflightPanic(t *testing.T) {
+	defer clear()
+	release := make(chan struct{})
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(context.Context, DummyRequest1) (DummyResponse1, error) {
+		<-release
+		panic("dummy")
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithSingleflight()); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	const n = 3
+	panics := make(chan interface{}, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer func() { panics <- recover() }()
+			_, _ = Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{})
+		}()
+	}
+	// Give all senders a chance to join the in-flight execution.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for i := 0; i < n; i++ {
+		if p := <-panics; p != "dummy" {
+			t.Errorf("want panic propagated to every sender, got %v", p)
+		}
+	}
+}
+
+func TestSend_SingleflightLeaderContextCanceled(t *testing.T) {
+	defer clear()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var hf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, req DummyRequest1) (DummyResponse1, error) {
+		close(entered)
+		select {
+		case <-release:
+			return DummyResponse1{String: req.String}, nil
+		case <-ctx.Done():
+			return DummyResponse1{}, ctx.Err()
+		}
+	}
+	if err := RegisterRequestHandler[DummyRequest1, DummyResponse1](hf, WithSingleflight()); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		_, err := Send[DummyRequest1, DummyResponse1](ctx, DummyRequest1{String: "dummy"})
+		leader <- err
+	}()
+	<-entered
+	follower := make(chan DummyResponse1)
+	go func() {
+		res, _ := Send[DummyRequest1, DummyResponse1](context.Background(), DummyRequest1{String: "dummy"})
+		follower <- res
+	}()
+	// Give the follower a chance to join the in-flight execution.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("want err %v, got %v", context.Canceled, err)
+	}
+	close(release)
+	if res := <-follower; res.String != "dummy" {
+		t.Errorf("want follower not affected by the leader's cancellation, got %v", res)
+	}
+}
+
+func TestRegister_SingleflightInvalidOption(t *testing.T) {
+	defer clear()
+	var rhf RequestHandlerFunc[[]string, DummyResponse1] = func(_ context.Context, _ []string) (DummyResponse1, error) {
+		return DummyResponse1{}, nil
+	}
+	if err := RegisterRequestHandler[[]string, DummyResponse1](rhf, WithSingleflight()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}, WithSingleflight()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+	}
+}
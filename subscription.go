@@ -0,0 +1,126 @@
+package mob
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// An OverflowPolicy determines the behavior of a Subscription when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks a notifier until there is room in the buffer or the notifier's context is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered event to make room for a notified one.
+	OverflowDropOldest
+	// OverflowDropNewest drops a notified event.
+	OverflowDropNewest
+)
+
+// A Subscription delivers notified events of a given type through a channel.
+type Subscription[T any] struct {
+	// dropped is accessed atomically, kept first for 64-bit alignment.
+	dropped uint64
+	// C is a channel notified events are delivered on. It's closed when the subscription is canceled.
+	C      <-chan T
+	c      chan T
+	policy OverflowPolicy
+	m      *Mob
+	hn     *handler
+	done   chan struct{}
+	once   sync.Once
+	// mu guards closing c, deliveries hold it for reading.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Cancel unregisters the subscription from its Mob instance and closes its channel.
+// Events already buffered can still be received. Cancel is idempotent.
+func (s *Subscription[T]) Cancel() {
+	s.once.Do(func() {
+		close(s.done)
+		var ev T
+		unregisterEventHandler(s.m, reflect.TypeOf(ev), s.hn)
+		s.mu.Lock()
+		s.closed = true
+		close(s.c)
+		s.mu.Unlock()
+	})
+}
+
+// Dropped returns the number of events dropped because the subscription's buffer was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription[T]) deliver(ctx context.Context, event T) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	switch s.policy {
+	case OverflowDropNewest:
+		select {
+		case s.c <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.c <- event:
+				return nil
+			default:
+			}
+			select {
+			case <-s.c:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.c <- event:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// SubscribeTo subscribes to events of a given type notified to the given Mob instance.
+// Notified events are delivered through the returned Subscription's channel buffered with a given size.
+// The policy determines the behavior when the buffer is full. Options are applied to the underlying event handler.
+//
+// Dropping policies require a buffer of size at least 1.
+func SubscribeTo[T any](m *Mob, size int, policy OverflowPolicy, opts ...Option) (*Subscription[T], error) {
+	if size < 0 || policy < OverflowBlock || policy > OverflowDropNewest {
+		return nil, fmt.Errorf("%w: invalid subscription size %d or policy %d", ErrInvalidOption, size, policy)
+	}
+	if size == 0 && policy != OverflowBlock {
+		return nil, fmt.Errorf("%w: dropping policy requires buffered subscription", ErrInvalidOption)
+	}
+	c := make(chan T, size)
+	s := &Subscription[T]{C: c, c: c, policy: policy, m: m, done: make(chan struct{})}
+	var ehf EventHandlerFunc[T] = s.deliver
+	hn, err := registerEventHandler[T](m, ehf, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.hn = hn
+	return s, nil
+}
+
+// Subscribe subscribes to events of a given type notified to the global Mob instance.
+// Notified events are delivered through the returned Subscription's channel buffered with a given size.
+// The policy determines the behavior when the buffer is full. Options are applied to the underlying event handler.
+//
+// Dropping policies require a buffer of size at least 1.
+func Subscribe[T any](size int, policy OverflowPolicy, opts ...Option) (*Subscription[T], error) {
+	return SubscribeTo[T](m, size, policy, opts...)
+}
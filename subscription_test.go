@@ -0,0 +1,124 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	defer clear()
+	s, err := Subscribe[DummyEvent1](3, OverflowBlock)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+			t.Fatalf("want success, got %v", err)
+		}
+	}
+	s.Cancel()
+	var got []int
+	for ev := range s.C {
+		got = append(got, ev.Int)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("want events [0 1 2], got %v", got)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != ErrHandlerNotFound {
+		t.Errorf("want subscription unregistered, got %v", err)
+	}
+	// Cancel must be idempotent.
+	s.Cancel()
+}
+
+func TestSubscribe_Overflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		want        []int
+		wantDropped uint64
+	}{
+		{
+			name:        "drop oldest",
+			policy:      OverflowDropOldest,
+			want:        []int{3, 4},
+			wantDropped: 3,
+		},
+		{
+			name:        "drop newest",
+			policy:      OverflowDropNewest,
+			want:        []int{0, 1},
+			wantDropped: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer clear()
+			s, err := Subscribe[DummyEvent1](2, tt.policy)
+			if err != nil {
+				t.Fatalf("subscribe: %v", err)
+			}
+			for i := 0; i < 5; i++ {
+				if err := Notify(context.Background(), DummyEvent1{Int: i}); err != nil {
+					t.Fatalf("want success, got %v", err)
+				}
+			}
+			s.Cancel()
+			var got []int
+			for ev := range s.C {
+				got = append(got, ev.Int)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("want events %v, got %v", tt.want, got)
+			}
+			if dropped := s.Dropped(); dropped != tt.wantDropped {
+				t.Errorf("want %d dropped, got %d", tt.wantDropped, dropped)
+			}
+		})
+	}
+}
+
+func TestSubscribe_OverflowBlock(t *testing.T) {
+	defer clear()
+	s, err := Subscribe[DummyEvent1](0, OverflowBlock)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Notify(ctx, DummyEvent1{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want err %v, got %v", context.DeadlineExceeded, err)
+	}
+	c := make(chan error)
+	go func() {
+		c <- Notify(context.Background(), DummyEvent1{})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// Cancel must unblock pending notifiers.
+	s.Cancel()
+	if err := <-c; err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+}
+
+func TestSubscribe_InvalidOption(t *testing.T) {
+	defer clear()
+	tests := []struct {
+		name   string
+		size   int
+		policy OverflowPolicy
+	}{
+		{name: "negative size", size: -1, policy: OverflowBlock},
+		{name: "unknown policy", size: 1, policy: OverflowPolicy(997)},
+		{name: "unbuffered dropping", size: 0, policy: OverflowDropOldest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Subscribe[DummyEvent1](tt.size, tt.policy); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("want err %v, got %v", ErrInvalidOption, err)
+			}
+		})
+	}
+}
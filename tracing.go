@@ -0,0 +1,184 @@
+package mob
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Names of spans started by a Mob instance.
+const (
+	// SpanSend is a name of a span around Send.
+	SpanSend = "mob.send"
+	// SpanNotify is a name of a span around Notify.
+	SpanNotify = "mob.notify"
+	// SpanHandle is a name of a span around an invocation of a single event handler.
+	SpanHandle = "mob.handle"
+)
+
+// Keys of attributes of spans started by a Mob instance.
+const (
+	// AttrType is a key of an attribute holding a name of a message's type in the Mob's type registry.
+	AttrType = "mob.type"
+	// AttrHandler is a key of an attribute holding a name of a handler. It's set for named handlers only.
+	AttrHandler = "mob.handler"
+	// AttrMessageID is a key of an attribute holding an ID of a message.
+	AttrMessageID = "mob.message_id"
+)
+
+// An Attribute is a key-value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Tracer provides an interface for tracing of requests and events handled by a Mob instance,
+// so an adapter of a tracing library, e.g. OpenTelemetry, can be plugged in.
+type Tracer interface {
+	// Start starts a span with a given name and attributes. The returned context carries the span,
+	// so spans started with it are its children.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// A Span is a traced operation.
+type Span interface {
+	// RecordError records an error the operation failed with.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// SetTracerTo sets a Tracer tracing the given Mob instance. A span is started around each Send,
+// each Notify and each invocation of an event handler. By default, a Mob instance isn't traced.
+func SetTracerTo(m *Mob, t Tracer) {
+	m.tracer = t
+}
+
+// SetTracer sets a Tracer tracing the global Mob instance.
+func SetTracer(t Tracer) {
+	SetTracerTo(m, t)
+}
+
+// trace starts a span with a given name around handling of a given message by a given handler, if any.
+// The returned function records an error of handling, if any, and ends the span.
+func trace(ctx context.Context, m *Mob, name string, msg interface{}, hn *handler) (context.Context, func(error)) {
+	if m.tracer == nil {
+		return ctx, nopEnd
+	}
+	attrs := make([]Attribute, 0, 3)
+	attrs = append(attrs, Attribute{Key: AttrType, Value: TypeNameTo(m, msg)})
+	if hn != nil && hn.name != "" {
+		attrs = append(attrs, Attribute{Key: AttrHandler, Value: hn.name})
+	}
+	if id := MessageID(ctx); id != "" {
+		attrs = append(attrs, Attribute{Key: AttrMessageID, Value: id})
+	}
+	ctx, span := m.tracer.Start(ctx, name, attrs...)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// NopTracer is a Tracer whose spans do nothing.
+type NopTracer struct{}
+
+// Start returns the given context and a span doing nothing.
+func (NopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) RecordError(error) {}
+func (nopSpan) End()              {}
+
+// A RecordedSpan is a span recorded by a RecordingTracer.
+type RecordedSpan struct {
+	// ID is an identifier of the span, unique within its RecordingTracer.
+	ID int
+	// ParentID is an ID of the span's parent, zero if the span is a root.
+	ParentID   int
+	Name       string
+	Attributes []Attribute
+	// Errs are errors recorded by the span.
+	Errs  []error
+	Start time.Time
+	End   time.Time
+}
+
+// Attribute returns a value of an attribute with a given key and whether the attribute exists.
+func (s RecordedSpan) Attribute(key string) (string, bool) {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// A RecordingTracer is a Tracer recording ended spans in memory, e.g. for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	last  int
+	spans []RecordedSpan
+}
+
+// NewRecordingTracer returns a RecordingTracer without recorded spans.
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// A recordingSpanKey is a context key of an ID of a span started by a RecordingTracer.
+type recordingSpanKey struct{}
+
+// Start starts a span, a child of a span started by the tracer carried by the given context, if any.
+func (t *RecordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(recordingSpanKey{}).(int)
+	t.mu.Lock()
+	t.last++
+	id := t.last
+	t.mu.Unlock()
+	s := &recordingSpan{t: t, s: RecordedSpan{ID: id, ParentID: parent, Name: name, Attributes: attrs, Start: time.Now()}}
+	return context.WithValue(ctx, recordingSpanKey{}, id), s
+}
+
+// Spans returns ended spans in order they ended.
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spans := make([]RecordedSpan, len(t.spans))
+	copy(spans, t.spans)
+	return spans
+}
+
+// Reset removes recorded spans.
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	t.spans = nil
+	t.mu.Unlock()
+}
+
+type recordingSpan struct {
+	t  *RecordingTracer
+	mu sync.Mutex
+	s  RecordedSpan
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	s.s.Errs = append(s.s.Errs, err)
+	s.mu.Unlock()
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	s.s.End = time.Now()
+	rs := s.s
+	s.mu.Unlock()
+	s.t.mu.Lock()
+	s.t.spans = append(s.t.spans, rs)
+	s.t.mu.Unlock()
+}
@@ -0,0 +1,82 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSetTracer(t *testing.T) {
+	m := New()
+	tr := NewRecordingTracer()
+	SetTracerTo(m, tr)
+	var rhf RequestHandlerFunc[DummyRequest1, DummyResponse1] = func(ctx context.Context, _ DummyRequest1) (DummyResponse1, error) {
+		return DummyResponse1{}, NewEventNotifier[DummyEvent1](m).Notify(ctx, DummyEvent1{})
+	}
+	if err := RegisterRequestHandlerTo[DummyRequest1, DummyResponse1](m, rhf, WithName("Request")); err != nil {
+		t.Fatalf("register request handler: %v", err)
+	}
+	failing := &DummyEventHandler1{handleFunc: func(context.Context, DummyEvent1) error { return errors.New("dummy") }}
+	if err := RegisterEventHandlerTo[DummyEvent1](m, failing, WithName("Failing")); err != nil {
+		t.Fatalf("register event handler: %v", err)
+	}
+	if _, err := NewRequestSender[DummyRequest1, DummyResponse1](m).Send(context.Background(), DummyRequest1{}); err == nil {
+		t.Fatal("want err, got nil")
+	}
+	spans := tr.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("want 3 spans, got %v", spans)
+	}
+	// Spans end from the innermost one.
+	handle, notify, send := spans[0], spans[1], spans[2]
+	if send.Name != SpanSend || notify.Name != SpanNotify || handle.Name != SpanHandle {
+		t.Fatalf("want send, notify and handle spans, got %s, %s, %s", send.Name, notify.Name, handle.Name)
+	}
+	if send.ParentID != 0 || notify.ParentID != send.ID || handle.ParentID != notify.ID {
+		t.Errorf("want nested spans, got %v", spans)
+	}
+	for _, tt := range []struct {
+		s       RecordedSpan
+		typ     string
+		handler string
+	}{
+		{s: send, typ: "github.com/erni27/mob.DummyRequest1", handler: "Request"},
+		{s: notify, typ: "github.com/erni27/mob.DummyEvent1"},
+		{s: handle, typ: "github.com/erni27/mob.DummyEvent1", handler: "Failing"},
+	} {
+		if typ, _ := tt.s.Attribute(AttrType); typ != tt.typ {
+			t.Errorf("want %s span's type %s, got %s", tt.s.Name, tt.typ, typ)
+		}
+		if handler, _ := tt.s.Attribute(AttrHandler); handler != tt.handler {
+			t.Errorf("want %s span's handler %q, got %q", tt.s.Name, tt.handler, handler)
+		}
+		if _, ok := tt.s.Attribute(AttrMessageID); !ok {
+			t.Errorf("want %s span's message ID", tt.s.Name)
+		}
+		if len(tt.s.Errs) != 1 {
+			t.Errorf("want %s span's error recorded, got %v", tt.s.Name, tt.s.Errs)
+		}
+		if tt.s.End.Before(tt.s.Start) {
+			t.Errorf("want %s span ended after start", tt.s.Name)
+		}
+	}
+	tr.Reset()
+	if spans := tr.Spans(); len(spans) != 0 {
+		t.Errorf("want no spans after reset, got %v", spans)
+	}
+}
+
+func TestNopTracer(t *testing.T) {
+	defer clear()
+	SetTracer(NopTracer{})
+	if err := RegisterEventHandler[DummyEvent1](&DummyEventHandler4{}); err != nil {
+		t.Fatalf("register handler: %v", err)
+	}
+	if err := Notify(context.Background(), DummyEvent1{}); err != nil {
+		t.Errorf("want success, got %v", err)
+	}
+	ctx := context.Background()
+	if sctx, _ := (NopTracer{}).Start(ctx, SpanSend); sctx != ctx {
+		t.Error("want context unchanged")
+	}
+}
@@ -0,0 +1,65 @@
+package mob
+
+import (
+	"context"
+	"reflect"
+)
+
+// EventWaiter is the interface that wraps the mob's WaitFor method.
+type EventWaiter[T any] interface {
+	// WaitFor blocks until an event T matching a given predicate is notified and returns the event.
+	// A nil predicate matches any event.
+	//
+	// If the context is done before a matching event is notified, the context's error is returned.
+	WaitFor(ctx context.Context, pred func(ctx context.Context, event T) bool) (T, error)
+}
+
+// NewEventWaiter returns an event waiter which uses a given Mob instance.
+func NewEventWaiter[T any](m *Mob) EventWaiter[T] {
+	return &waiter[T]{m: m}
+}
+
+// A waiter is a facilitator for a given event type.
+type waiter[T any] struct {
+	m *Mob
+}
+
+func (w *waiter[T]) WaitFor(ctx context.Context, pred func(ctx context.Context, event T) bool) (T, error) {
+	// Buffered, so a one-shot handler never blocks a notifier.
+	c := make(chan T, 1)
+	var ehf EventHandlerFunc[T] = func(_ context.Context, event T) error {
+		c <- event
+		return nil
+	}
+	opts := []Option{WithOnce()}
+	if pred != nil {
+		opts = append(opts, WithFilter(pred))
+	}
+	hn, err := registerEventHandler[T](w.m, ehf, opts)
+	if err != nil {
+		var ev T
+		return ev, err
+	}
+	select {
+	case ev := <-c:
+		return ev, nil
+	case <-ctx.Done():
+	}
+	var ev T
+	unregisterEventHandler(w.m, reflect.TypeOf(ev), hn)
+	// The handler might have claimed an event before it's unregistered.
+	select {
+	case ev := <-c:
+		return ev, nil
+	default:
+		return ev, ctx.Err()
+	}
+}
+
+// WaitFor blocks until an event T matching a given predicate is notified to the global Mob instance
+// and returns the event. A nil predicate matches any event.
+//
+// If the context is done before a matching event is notified, the context's error is returned.
+func WaitFor[T any](ctx context.Context, pred func(ctx context.Context, event T) bool) (T, error) {
+	return NewEventWaiter[T](m).WaitFor(ctx, pred)
+}
@@ -0,0 +1,53 @@
+package mob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitFor(t *testing.T) {
+	defer clear()
+	type result struct {
+		ev  DummyEvent1
+		err error
+	}
+	c := make(chan result)
+	go func() {
+		ev, err := WaitFor(context.Background(), func(_ context.Context, ev DummyEvent1) bool { return ev.String == "wanted" })
+		c <- result{ev: ev, err: err}
+	}()
+	// Wait until the waiter is registered.
+	for {
+		if err := Notify(context.Background(), DummyEvent1{String: "unwanted"}); err != ErrHandlerNotFound {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := Notify(context.Background(), DummyEvent1{String: "wanted", Int: 997}); err != nil {
+		t.Fatalf("want success, got %v", err)
+	}
+	r := <-c
+	if r.err != nil {
+		t.Fatalf("want success, got %v", r.err)
+	}
+	if r.ev.String != "wanted" || r.ev.Int != 997 {
+		t.Errorf("want matching event, got %v", r.ev)
+	}
+	if err := Notify(context.Background(), DummyEvent1{String: "wanted"}); err != ErrHandlerNotFound {
+		t.Errorf("want waiter unregistered, got %v", err)
+	}
+}
+
+func TestWaitFor_ContextDone(t *testing.T) {
+	m := New()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := NewEventWaiter[DummyEvent1](m).WaitFor(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want err %v, got %v", context.DeadlineExceeded, err)
+	}
+	if err := NewEventNotifier[DummyEvent1](m).Notify(context.Background(), DummyEvent1{}); err != ErrHandlerNotFound {
+		t.Errorf("want waiter unregistered, got %v", err)
+	}
+}